@@ -4,6 +4,15 @@ export class Socket {
 		[k: string]: (_: any) => void
 	} = {};
 	private queuedEmitsBeforeConn: { evName: string, data: any }[] = [];
+	// number of the last logged event received, see onSeq.
+	lastSeq = 0;
+	private seqCb: ((seq: number) => void) | null = null;
+
+	// cb gets the number of every new logged event. Events with a number
+	// already seen are dropped.
+	onSeq(cb: (seq: number) => void) {
+		this.seqCb = cb;
+	}
 
 	onClose(cb: () => void) {
 		const closeEventCb = () => {
@@ -17,7 +26,12 @@ export class Socket {
 		this.conn = new WebSocket(url);
 		this.conn.onerror = onerror;
 		this.conn.addEventListener("message", (e: MessageEvent) => {
-			const msg: { eventName: string, data: string } = JSON.parse(e.data);
+			const msg: { eventName: string, data: string, seq?: number } = JSON.parse(e.data);
+			if (msg.seq) {
+				if (msg.seq <= this.lastSeq) return;
+				this.lastSeq = msg.seq;
+				this.seqCb?.(msg.seq);
+			}
 			if (!(msg.eventName in this.eventMap)) return;
 			try {
 				const json = JSON.parse(msg.data);
@@ -15,6 +15,17 @@ export type EmailDetails = {
 	isCatchAll: boolean,
 	isInboxFull: boolean,
 	errorMsg: { String: string, Valid: boolean },
+	errorCode: string,
+	isRoleAccount: boolean,
+	isFree: boolean,
+	suggestion: string,
+	hasGravatar: boolean,
+	gravatarUrl: string,
+	verifiedAt: { String: string, Valid: boolean },
+	originalEmailId: string,
+	score: number,
+	category: "" | "safe" | "risky" | "invalid" | "unknown",
+	matchedList: "" | "disposable" | "blocked" | "trusted",
 }
 
 export class FileStats {
@@ -31,6 +42,15 @@ export class FileStats {
 	inboxFull: number = 0
 	hostExists: number = 0
 	errored: number = 0
+	categories: CategoryStats = new CategoryStats()
+}
+
+export class CategoryStats {
+	safe: number = 0
+	risky: number = 0
+	invalid: number = 0
+	unknown: number = 0
+	averageScore: number = 0
 }
 
 export class ProxyDetails {
@@ -43,4 +63,18 @@ export class ProxyDetails {
 	password: string = ""
 	isInUse: boolean = false
 	isEnabled: boolean = true
+	isHealthy: boolean = true
+	successCount: number = 0
+	failureCount: number = 0
+	lastError: string = ""
+	lastErrorDateTime: string = ""
+	lastSuccessDateTime: string = ""
+}
+
+export class DomainListEntry {
+	id: number = 0
+	userId: string = ""
+	list: "disposable" | "blocked" | "trusted" = "disposable"
+	domain: string = ""
+	createdDateTime: string = ""
 }
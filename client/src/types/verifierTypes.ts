@@ -3,17 +3,49 @@ export interface ProgressData {
 	progress: number,
 	success: number,
 	failed: number,
-	retry: number
+	retry: number,
+	deferred: number,
+	inFlight: number
 }
 
 export enum Status {
 	NotCreated = "not created",
 	Created = "created",
+	Queued = "queued",
 	Running = "running",
+	Paused = "paused",
+	Cancelled = "cancelled",
+	Interrupted = "interrupted",
 	Done = "done",
 	Err = "err"
 }
 
+export interface RetryPolicy {
+	maxRetries: number,
+	baseDelayMs: number,
+	maxDelayMs: number,
+	multiplier: number,
+	jitter: number,
+	rules: { [code: string]: { maxRetries: number, baseDelayMs: number } },
+}
+
+export interface ProxyHealth {
+	successes: number,
+	failures: number,
+	consecutiveFailures: number,
+	isHealthy: boolean,
+	disabledUntil: string,
+	lastError: string,
+	lastErrorAt: string,
+	lastSuccessAt: string,
+}
+
+export interface SMTPIdentity {
+	heloName: string,
+	fromEmail: string,
+	probeUsername: string,
+}
+
 export interface VerifierDetails {
 	batchSize: number,
 	completedBatches: { [_:number]: ProgressData[] },
@@ -21,9 +53,28 @@ export interface VerifierDetails {
 	currentBatchSize: number,
 	currentProgressList: ProgressData[]
 	delayMs: number
+	concurrency: number,
+	checker: string,
 	emailCount: number,
 	proxies: string[],
+	hostLimit: { by: "domain" | "mx", maxConns: number, ratePerSec: number },
+	priority: number,
+	startedAt: string,
+	reverify: { olderThanDays: number, outcomes: string[] },
+	flushIntervalMs: number,
+	flushSize: number,
+	greylistRecheck: { delayMs: number, maxRechecks: number },
+	checkGravatar: boolean,
+	userId: string,
+	smtpIdentity: SMTPIdentity,
+	usedSmtpIdentity: SMTPIdentity,
 	retryCount: number,
+	retryPolicy: RetryPolicy,
+	proxyFailover: { maxFailures: number, cooldownMs: number },
+	proxyHealth: ProxyHealth[],
+	proxyStrategy: string,
+	proxyWeights: number[],
+	inFlightProxies: { [email: string]: number },
 	state: Status
 }
 
@@ -43,6 +43,9 @@ export const filterers: {[_:string]: FilterCycler} = {
 	isDisposable: new FilterCycler(twoStateClassNames, twoStateCmp),
 	isCatchAll: new FilterCycler(twoStateClassNames, twoStateCmp),
 	isInboxFull: new FilterCycler(twoStateClassNames, twoStateCmp),
+	isRoleAccount: new FilterCycler(twoStateClassNames, twoStateCmp),
+	isFree: new FilterCycler(twoStateClassNames, twoStateCmp),
+	category: new FilterCycler(['green', 'yellow', 'red', 'gray'], ['safe', 'risky', 'invalid', 'unknown']),
 }
 
 export function getToFilter() {
@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"database/sql"
+	"email_verify/schema"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -43,11 +46,13 @@ func GetEmailsForVerification(db *sql.DB, fileId int64) ([]string, error) {
 	return emails, nil
 }
 
-func GetToVerifyCount(db *sql.DB, fileId int64) (int64, error) {
-	query := `
+func GetToVerifyCount(db *sql.DB, fileId int64, opts schema.ReverifyOptions) (int64, error) {
+	cond, args := toVerifyCondition(opts, time.Now())
+
+	query := fmt.Sprintf(`
 	select count(*)
 	from emails
-	where (file_id = ?) and (error_msg is null or error_msg != '')`
+	where (file_id = ?) and %s`, cond)
 
 	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancelfunc()
@@ -60,7 +65,7 @@ func GetToVerifyCount(db *sql.DB, fileId int64) (int64, error) {
 
 	defer stmt.Close()
 
-	row := stmt.QueryRowContext(ctx, fileId)
+	row := stmt.QueryRowContext(ctx, append([]any{fileId}, args...)...)
 
 	if err = row.Err(); row.Err() != nil {
 		return 0, err
@@ -109,3 +114,77 @@ func GetTotalEmailCount(db *sql.DB, fileId int64) (int64, error) {
 
 	return count, nil
 }
+
+var outcomeConditions = map[string]string{
+	schema.OUTCOME_UNKNOWN: "reachable = 'unknown'",
+	schema.OUTCOME_CATCH_ALL: "is_catch_all = 1",
+	schema.OUTCOME_UNDELIVERABLE: "reachable = 'no'",
+	schema.OUTCOME_ERRORED: "error_code != ''",
+}
+
+// toVerifyCondition selects the emails still to be verified plus, when
+// opts is set, the verified ones it picks. Emails verified at or after
+// verifiedBefore are never picked so a resumed run doesn't check the
+// emails it already did again.
+func toVerifyCondition(opts schema.ReverifyOptions, verifiedBefore time.Time) (string, []any) {
+	pending := "(error_msg is null or error_msg != '')"
+
+	if !opts.IsSet() {
+		return pending, nil
+	}
+
+	stale := []string{"(verified_at is null or verified_at < ?)"}
+	args := []any{verifiedBefore}
+
+	if opts.OlderThanDays > 0 {
+		stale = append(stale, "(verified_at is null or verified_at < ?)")
+		args = append(args, time.Now().AddDate(0, 0, -opts.OlderThanDays))
+	}
+
+	if len(opts.Outcomes) > 0 {
+		outcomes := []string{}
+		for _, o := range opts.Outcomes {
+			outcomes = append(outcomes, "("+outcomeConditions[o]+")")
+		}
+		stale = append(stale, "("+strings.Join(outcomes, " or ")+")")
+	}
+
+	return fmt.Sprintf("(%s or (%s))", pending, strings.Join(stale, " and ")), args
+}
+
+// GetEmailsForReverification is GetEmailsForVerification with the
+// verified emails picked by opts added.
+func GetEmailsForReverification(db *sql.DB, fileId int64, opts schema.ReverifyOptions, verifiedBefore time.Time) ([]string, error) {
+	if !opts.IsSet() {
+		return GetEmailsForVerification(db, fileId)
+	}
+
+	cond, args := toVerifyCondition(opts, verifiedBefore)
+
+	query := fmt.Sprintf(`select email_id from emails where (file_id = ?) and %s`, cond)
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, query, append([]any{fileId}, args...)...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	emails := []string{}
+
+	for rows.Next() {
+		var email string
+
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+
+		emails = append(emails, email)
+	}
+
+	return emails, rows.Err()
+}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"email_verify/schema"
+	"errors"
+	"time"
+)
+
+const domainListColumns = `id, user_id, list, domain, created_at`
+
+// GetDomainListEntries returns the entries of the user, of every list if
+// list is empty.
+func GetDomainListEntries(db *sql.DB, userId, list string) ([]schema.DomainListEntry, error) {
+	query := `select ` + domainListColumns + ` from domain_lists where user_id = ?`
+	args := []any{userId}
+
+	if list != "" {
+		query += ` and list = ?`
+		args = append(args, list)
+	}
+
+	query += ` order by list, domain`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []schema.DomainListEntry{}
+
+	for rows.Next() {
+		var e schema.DomainListEntry
+
+		if err := rows.Scan(&e.Id, &e.UserId, &e.List, &e.Domain, &e.CreatedDateTime); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetDomainListMap returns the listed domains of the user with the list
+// each is on.
+func GetDomainListMap(db *sql.DB, userId string) (map[string]string, error) {
+	lists := map[string]string{}
+
+	if userId == "" {
+		return lists, nil
+	}
+
+	entries, err := GetDomainListEntries(db, userId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		lists[e.Domain] = e.List
+	}
+
+	return lists, nil
+}
+
+// SaveDomainListEntry puts the domain of e on its list. A domain is on one
+// list per user, so one that is already listed moves to the new list.
+func SaveDomainListEntry(db *sql.DB, e schema.DomainListEntry) error {
+	query := `
+	insert into domain_lists (user_id, list, domain, created_at)
+	values (?, ?, ?, ?)
+	on duplicate key update list = values(list)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err := db.ExecContext(ctx, query, e.UserId, e.List, e.Domain, time.Now())
+
+	return err
+}
+
+func UpdateDomainListEntry(db *sql.DB, e schema.DomainListEntry) error {
+	query := `update domain_lists set list = ?, domain = ? where id = ? and user_id = ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err := db.ExecContext(ctx, query, e.List, e.Domain, e.Id, e.UserId)
+
+	return err
+}
+
+func DeleteDomainListEntry(db *sql.DB, userId string, id int64) error {
+	query := `delete from domain_lists where id = ? and user_id = ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	res, err := db.ExecContext(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+// ImportDomainList saves domains to list in one transaction and returns
+// how many were added or moved.
+func ImportDomainList(db *sql.DB, userId, list string, domains []string) (int64, error) {
+	query := `
+	insert into domain_lists (user_id, list, domain, created_at)
+	values (?, ?, ?, ?)
+	on duplicate key update list = values(list)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelfunc()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	var count int64
+
+	for _, d := range domains {
+		res, err := stmt.ExecContext(ctx, userId, list, d, now)
+		if err != nil {
+			return 0, err
+		}
+
+		if n, _ := res.RowsAffected(); n > 0 {
+			count++
+		}
+	}
+
+	return count, tx.Commit()
+}
+
+// checkAffected fails when res didn't touch any row. Updates can't use it
+// as mysql doesn't count the rows they leave as they were.
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return errors.New("Record not found.")
+	}
+
+	return nil
+}
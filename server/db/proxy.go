@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"email_verify/schema"
+	"time"
+)
+
+func SaveProxyHealth(db *sql.DB, h schema.ProxyHealth) error {
+	query := `
+	insert into proxy_health (
+		proxy_id, is_in_use, is_healthy, success_count, failure_count,
+		consecutive_failures, last_error, last_error_at, last_success_at
+	)
+	values (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	on duplicate key update
+		is_in_use = values(is_in_use),
+		is_healthy = values(is_healthy),
+		success_count = values(success_count),
+		failure_count = values(failure_count),
+		consecutive_failures = values(consecutive_failures),
+		last_error = values(last_error),
+		last_error_at = coalesce(values(last_error_at), last_error_at),
+		last_success_at = coalesce(values(last_success_at), last_success_at)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err := db.ExecContext(
+		ctx, query,
+		h.ProxyId,
+		h.IsInUse,
+		h.IsHealthy,
+		h.SuccessCount,
+		h.FailureCount,
+		h.ConsecutiveFailures,
+		h.LastError,
+		h.LastErrorAt,
+		h.LastSuccessAt,
+	)
+
+	return err
+}
+
+// MergeProxyHealth fills in the health columns of list from the
+// proxy_health table.
+func MergeProxyHealth(db *sql.DB, list []schema.ProxyDetails) error {
+	query := `
+	select proxy_id, is_in_use, is_healthy, success_count, failure_count,
+		last_error, last_error_at, last_success_at
+	from proxy_health`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	type health struct {
+		isInUse bool
+		isHealthy bool
+		successCount int64
+		failureCount int64
+		lastError string
+		lastErrorAt sql.NullString
+		lastSuccessAt sql.NullString
+	}
+
+	byId := make(map[int64]health)
+
+	for rows.Next() {
+		var id int64
+		var h health
+
+		if err := rows.Scan(
+			&id,
+			&h.isInUse,
+			&h.isHealthy,
+			&h.successCount,
+			&h.failureCount,
+			&h.lastError,
+			&h.lastErrorAt,
+			&h.lastSuccessAt,
+		); err != nil {
+			return err
+		}
+
+		byId[id] = h
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range list {
+		p := &list[i]
+		p.IsHealthy = true
+
+		h, ok := byId[p.Id]
+		if !ok {
+			continue
+		}
+
+		p.IsInUse = p.IsInUse || h.isInUse
+		p.IsHealthy = h.isHealthy
+		p.SuccessCount = h.successCount
+		p.FailureCount = h.failureCount
+		p.LastError = h.lastError
+		p.LastErrorDateTime = h.lastErrorAt.String
+		p.LastSuccessDateTime = h.lastSuccessAt.String
+	}
+
+	return nil
+}
+
+func GetProxyList(db *sql.DB, userId string) ([]schema.ProxyDetails, error) {
+	q := `call sp_get_proxy_list(?)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, q, userId)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []schema.ProxyDetails{}
+
+	for rows.Next() {
+		var p schema.ProxyDetails
+
+		if err := rows.Scan(
+			&p.Id,
+			&p.Proto,
+			&p.Host,
+			&p.Port,
+			&p.Name,
+			&p.Password,
+			&p.IsInUse,
+			&p.IsEnabled,
+		); err != nil {
+			return nil, err
+		}
+
+		p.UserId = userId
+		list = append(list, p)
+	}
+
+	return list, rows.Err()
+}
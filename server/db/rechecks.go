@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"email_verify/schema"
+	"time"
+)
+
+// SaveRecheck queues r to be checked at dueAt, replacing the entry of the
+// same email if there is one.
+func SaveRecheck(db *sql.DB, r schema.EmailRecheck, dueAt time.Time) error {
+	query := `
+	insert into email_rechecks (file_id, email_id, user_id, checker, proxy, helo_name, mail_from, probe_username, gravatar, attempts, max_attempts, delay_ms, last_error, due_at)
+	values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	on duplicate key update
+		user_id = values(user_id),
+		helo_name = values(helo_name),
+		mail_from = values(mail_from),
+		probe_username = values(probe_username),
+		gravatar = values(gravatar),
+		checker = values(checker),
+		proxy = values(proxy),
+		attempts = values(attempts),
+		max_attempts = values(max_attempts),
+		delay_ms = values(delay_ms),
+		last_error = values(last_error),
+		due_at = values(due_at)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err := db.ExecContext(ctx, query, r.FileId, r.EmailId, r.UserId, r.Checker, r.Proxy,
+		r.SMTPIdentity.HeloName, r.SMTPIdentity.FromEmail, r.SMTPIdentity.ProbeUsername,
+		r.Gravatar, r.Attempts, r.MaxAttempts, r.DelayMs, r.LastError, dueAt)
+
+	return err
+}
+
+func DeleteRecheck(db *sql.DB, fileId int64, emailId string) error {
+	query := `delete from email_rechecks where file_id = ? and email_id = ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err := db.ExecContext(ctx, query, fileId, emailId)
+
+	return err
+}
+
+const recheckColumns = `file_id, email_id, user_id, checker, proxy, helo_name, mail_from, probe_username, gravatar, attempts, max_attempts, delay_ms, last_error, due_at`
+
+func getRechecks(db *sql.DB, query string, args ...any) ([]schema.EmailRecheck, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rechecks := []schema.EmailRecheck{}
+
+	for rows.Next() {
+		var r schema.EmailRecheck
+
+		err := rows.Scan(
+			&r.FileId,
+			&r.EmailId,
+			&r.UserId,
+			&r.Checker,
+			&r.Proxy,
+			&r.SMTPIdentity.HeloName,
+			&r.SMTPIdentity.FromEmail,
+			&r.SMTPIdentity.ProbeUsername,
+			&r.Gravatar,
+			&r.Attempts,
+			&r.MaxAttempts,
+			&r.DelayMs,
+			&r.LastError,
+			&r.DueDateTime,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rechecks = append(rechecks, r)
+	}
+
+	return rechecks, rows.Err()
+}
+
+// GetDueRechecks returns up to limit rechecks that are due at now,
+// oldest first.
+func GetDueRechecks(db *sql.DB, now time.Time, limit int) ([]schema.EmailRecheck, error) {
+	query := `select ` + recheckColumns + ` from email_rechecks where due_at <= ? order by due_at limit ?`
+	return getRechecks(db, query, now, limit)
+}
+
+func GetRechecks(db *sql.DB, fileId int64) ([]schema.EmailRecheck, error) {
+	query := `select ` + recheckColumns + ` from email_rechecks where file_id = ? order by due_at`
+	return getRechecks(db, query, fileId)
+}
+
+func GetPendingRecheckCount(db *sql.DB, fileId int64) (int64, error) {
+	query := `select count(*) from email_rechecks where file_id = ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	var count int64
+
+	err := db.QueryRowContext(ctx, query, fileId).Scan(&count)
+
+	return count, err
+}
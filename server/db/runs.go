@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"email_verify/schema"
+	"strings"
+	"time"
+)
+
+func SaveRun(db *sql.DB, fileId int64, state string, data []byte) error {
+	query := `
+	insert into verifier_runs (file_id, state, data)
+	values (?, ?, ?)
+	on duplicate key update state = values(state), data = values(data)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err := db.ExecContext(ctx, query, fileId, state, data)
+
+	return err
+}
+
+func DeleteRun(db *sql.DB, fileId int64) error {
+	query := `delete from verifier_runs where file_id = ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err := db.ExecContext(ctx, query, fileId)
+
+	return err
+}
+
+// GetRun returns the saved run of fileId, or sql.ErrNoRows if it has none.
+func GetRun(db *sql.DB, fileId int64) (schema.VerifierRun, error) {
+	query := `select file_id, state, data, updated_at from verifier_runs where file_id = ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	var r schema.VerifierRun
+
+	err := db.QueryRowContext(ctx, query, fileId).Scan(&r.FileId, &r.State, &r.Data, &r.UpdatedDateTime)
+
+	return r, err
+}
+
+// GetRunsInState returns the saved runs whose state is one of states.
+func GetRunsInState(db *sql.DB, states ...string) ([]schema.VerifierRun, error) {
+	query := `select file_id, state, data, updated_at from verifier_runs where find_in_set(state, ?)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, query, strings.Join(states, ","))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	runs := []schema.VerifierRun{}
+
+	for rows.Next() {
+		var r schema.VerifierRun
+
+		if err := rows.Scan(&r.FileId, &r.State, &r.Data, &r.UpdatedDateTime); err != nil {
+			return nil, err
+		}
+
+		runs = append(runs, r)
+	}
+
+	return runs, rows.Err()
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"email_verify/schema"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// GetScoreWeights returns the score weights of the user. Users that never
+// saved theirs get the defaults, as do the fields missing from theirs.
+func GetScoreWeights(db *sql.DB, userId string) (schema.ScoreWeights, error) {
+	w := schema.DefaultScoreWeights()
+
+	if userId == "" {
+		return w, nil
+	}
+
+	query := `select weights from score_weights where user_id = ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	var data []byte
+
+	err := db.QueryRowContext(ctx, query, userId).Scan(&data)
+
+	if err == sql.ErrNoRows {
+		return w, nil
+	} else if err != nil {
+		return w, err
+	}
+
+	err = json.Unmarshal(data, &w)
+
+	return w, err
+}
+
+func SaveScoreWeights(db *sql.DB, userId string, w schema.ScoreWeights) error {
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	insert into score_weights (user_id, weights)
+	values (?, ?)
+	on duplicate key update weights = values(weights)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err = db.ExecContext(ctx, query, userId, data)
+
+	return err
+}
+
+// GetCategoryStats counts the verified emails of the file per category.
+func GetCategoryStats(db *sql.DB, fileId int64) (schema.CategoryStats, error) {
+	stats, err := GetFilesCategoryStats(db, []int64{fileId})
+	return stats[fileId], err
+}
+
+// GetFilesCategoryStats counts the verified emails of each file per
+// category, in a single query for all of them. Files without emails are
+// left out.
+func GetFilesCategoryStats(db *sql.DB, fileIds []int64) (map[int64]schema.CategoryStats, error) {
+	stats := make(map[int64]schema.CategoryStats, len(fileIds))
+
+	if len(fileIds) == 0 {
+		return stats, nil
+	}
+
+	query := `
+	select
+		file_id,
+		sum(category = 'safe'),
+		sum(category = 'risky'),
+		sum(category = 'invalid'),
+		sum(category = 'unknown'),
+		coalesce(avg(case when category != '' then score end), 0)
+	from emails
+	where file_id in (?` + strings.Repeat(", ?", len(fileIds) - 1) + `)
+	group by file_id`
+
+	args := make([]any, len(fileIds))
+	for i, id := range fileIds {
+		args[i] = id
+	}
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return stats, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var fileId int64
+		var s schema.CategoryStats
+
+		if err := rows.Scan(
+			&fileId,
+			&s.Safe,
+			&s.Risky,
+			&s.Invalid,
+			&s.Unknown,
+			&s.AverageScore,
+		); err != nil {
+			return stats, err
+		}
+
+		stats[fileId] = s
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"email_verify/schema"
+	"strings"
+	"time"
+)
+
+// correctEmail replaces the domain of email with suggestion.
+func correctEmail(email, suggestion string) string {
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return ""
+	}
+
+	return email[:at+1] + suggestion
+}
+
+// GetSuggestions returns the emails of the file that have a domain
+// suggestion which wasn't accepted yet.
+func GetSuggestions(db *sql.DB, fileId, from, limit int64) ([]schema.EmailSuggestion, error) {
+	query := `
+	select email_id, suggestion
+	from emails
+	where file_id = ? and suggestion != '' and original_email_id = ''
+	order by email_id
+	limit ?, ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, query, fileId, from, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	suggestions := []schema.EmailSuggestion{}
+
+	for rows.Next() {
+		var s schema.EmailSuggestion
+
+		if err := rows.Scan(&s.EmailId, &s.Suggestion); err != nil {
+			return nil, err
+		}
+
+		s.CorrectedEmailId = correctEmail(s.EmailId, s.Suggestion)
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, rows.Err()
+}
+
+func GetSuggestionCount(db *sql.DB, fileId int64) (int64, error) {
+	query := `select count(*) from emails where file_id = ? and suggestion != '' and original_email_id = ''`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	var count int64
+
+	err := db.QueryRowContext(ctx, query, fileId).Scan(&count)
+
+	return count, err
+}
+
+// AcceptSuggestions rewrites the emails of the file to their suggested
+// address, keeping the old one in original_email_id, and clears all of
+// their results so they get verified again. With all set, every pending
+// suggestion of the file is accepted and emailIds is ignored.
+// Suggestions whose corrected address is already in the file are skipped.
+// The emails are rewritten in one transaction, so on an error none are.
+func AcceptSuggestions(db *sql.DB, fileId int64, emailIds []string, all bool) ([]schema.AcceptedSuggestion, []schema.SkippedSuggestion, error) {
+	var suggestions []schema.EmailSuggestion
+
+	if all {
+		count, err := GetSuggestionCount(db, fileId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		suggestions, err = GetSuggestions(db, fileId, 0, count)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		var err error
+		suggestions, err = getSuggestionsOf(db, fileId, emailIds)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	accepted := []schema.AcceptedSuggestion{}
+	skipped := []schema.SkippedSuggestion{}
+
+	query := `
+	update emails
+	set
+		original_email_id = email_id,
+		email_id = ?,
+		is_valid_syntax = 0,
+		reachable = '',
+		is_deliverable = 0,
+		is_host_exists = 0,
+		has_mx_records = 0,
+		is_disposable = 0,
+		is_catch_all = 0,
+		is_inbox_full = 0,
+		error_msg = NULL,
+		error_code = '',
+		is_role_account = 0,
+		is_free = 0,
+		suggestion = '',
+		has_gravatar = 0,
+		gravatar_url = '',
+		score = 0,
+		category = '',
+		matched_list = '',
+		verified_at = NULL
+	where file_id = ? and email_id = ? and original_email_id = ''
+		and not exists (
+			select 1 from (select email_id from emails where file_id = ?) as e
+			where e.email_id = ?
+		)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelfunc()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer stmt.Close()
+
+	for _, s := range suggestions {
+		if s.CorrectedEmailId == "" {
+			skipped = append(skipped, schema.SkippedSuggestion{EmailId: s.EmailId, Reason: "invalid email"})
+			continue
+		}
+
+		res, err := stmt.ExecContext(ctx, s.CorrectedEmailId, fileId, s.EmailId, fileId, s.CorrectedEmailId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if n, _ := res.RowsAffected(); n == 0 {
+			skipped = append(skipped, schema.SkippedSuggestion{EmailId: s.EmailId, Reason: "corrected email already exists"})
+			continue
+		}
+
+		accepted = append(accepted, schema.AcceptedSuggestion{OriginalEmailId: s.EmailId, EmailId: s.CorrectedEmailId})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return accepted, skipped, nil
+}
+
+func getSuggestionsOf(db *sql.DB, fileId int64, emailIds []string) ([]schema.EmailSuggestion, error) {
+	suggestions := []schema.EmailSuggestion{}
+
+	if len(emailIds) == 0 {
+		return suggestions, nil
+	}
+
+	query := `
+	select email_id, suggestion
+	from emails
+	where file_id = ? and suggestion != '' and original_email_id = ''
+		and email_id in (?` + strings.Repeat(", ?", len(emailIds)-1) + `)`
+
+	args := []any{fileId}
+	for _, e := range emailIds {
+		args = append(args, e)
+	}
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var s schema.EmailSuggestion
+
+		if err := rows.Scan(&s.EmailId, &s.Suggestion); err != nil {
+			return nil, err
+		}
+
+		s.CorrectedEmailId = correctEmail(s.EmailId, s.Suggestion)
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, rows.Err()
+}
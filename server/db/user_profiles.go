@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"email_verify/schema"
+	"time"
+)
+
+// GetUserProfile returns the profile of the user, empty if they never
+// saved one.
+func GetUserProfile(db *sql.DB, userId string) (schema.UserProfile, error) {
+	p := schema.UserProfile{UserId: userId}
+
+	if userId == "" {
+		return p, nil
+	}
+
+	query := `select helo_name, mail_from, probe_username from user_profiles where user_id = ?`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	err := db.QueryRowContext(ctx, query, userId).Scan(
+		&p.SMTPIdentity.HeloName,
+		&p.SMTPIdentity.FromEmail,
+		&p.SMTPIdentity.ProbeUsername,
+	)
+
+	if err == sql.ErrNoRows {
+		return p, nil
+	}
+
+	return p, err
+}
+
+func SaveUserProfile(db *sql.DB, p schema.UserProfile) error {
+	query := `
+	insert into user_profiles (user_id, helo_name, mail_from, probe_username)
+	values (?, ?, ?, ?)
+	on duplicate key update
+		helo_name = values(helo_name),
+		mail_from = values(mail_from),
+		probe_username = values(probe_username)`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	_, err := db.ExecContext(ctx, query,
+		p.UserId,
+		p.SMTPIdentity.HeloName,
+		p.SMTPIdentity.FromEmail,
+		p.SMTPIdentity.ProbeUsername,
+	)
+
+	return err
+}
@@ -0,0 +1,149 @@
+package dbconn
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// tables owned by the server itself rather than the stored procedures.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS verifier_runs (
+		file_id int NOT NULL,
+		state varchar(20) NOT NULL,
+		data json NOT NULL,
+		updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		PRIMARY KEY (file_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS proxy_health (
+		proxy_id int NOT NULL,
+		is_in_use tinyint NOT NULL DEFAULT '0',
+		is_healthy tinyint NOT NULL DEFAULT '1',
+		success_count int NOT NULL DEFAULT '0',
+		failure_count int NOT NULL DEFAULT '0',
+		consecutive_failures int NOT NULL DEFAULT '0',
+		last_error text NOT NULL,
+		last_error_at datetime DEFAULT NULL,
+		last_success_at datetime DEFAULT NULL,
+		PRIMARY KEY (proxy_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS email_rechecks (
+		file_id int NOT NULL,
+		email_id varchar(320) NOT NULL,
+		checker varchar(50) NOT NULL,
+		proxy varchar(500) NOT NULL DEFAULT '',
+		attempts int NOT NULL DEFAULT '0',
+		max_attempts int NOT NULL,
+		delay_ms int NOT NULL,
+		last_error text NOT NULL,
+		due_at datetime NOT NULL,
+		PRIMARY KEY (file_id, email_id),
+		KEY due_at (due_at)
+	)`,
+	`CREATE TABLE IF NOT EXISTS score_weights (
+		user_id varchar(255) NOT NULL,
+		weights text NOT NULL,
+		PRIMARY KEY (user_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS domain_lists (
+		id int NOT NULL AUTO_INCREMENT,
+		user_id varchar(255) NOT NULL,
+		list varchar(20) NOT NULL,
+		domain varchar(255) NOT NULL,
+		created_at datetime NOT NULL,
+		PRIMARY KEY (id),
+		UNIQUE KEY user_domain (user_id, domain)
+	)`,
+	`CREATE TABLE IF NOT EXISTS user_profiles (
+		user_id varchar(255) NOT NULL,
+		helo_name varchar(255) NOT NULL DEFAULT '',
+		mail_from varchar(320) NOT NULL DEFAULT '',
+		probe_username varchar(64) NOT NULL DEFAULT '',
+		PRIMARY KEY (user_id)
+	)`,
+}
+
+type column struct {
+	table string
+	name string
+	def string
+}
+
+// columns added to existing tables. They are only added when missing.
+var columns = []column{
+	{"emails", "error_code", "varchar(20) NOT NULL DEFAULT ''"},
+	{"emails", "verified_at", "datetime DEFAULT NULL"},
+	{"emails", "is_role_account", "tinyint NOT NULL DEFAULT '0'"},
+	{"emails", "is_free", "tinyint NOT NULL DEFAULT '0'"},
+	{"emails", "suggestion", "varchar(255) NOT NULL DEFAULT ''"},
+	{"emails", "has_gravatar", "tinyint NOT NULL DEFAULT '0'"},
+	{"emails", "gravatar_url", "varchar(255) NOT NULL DEFAULT ''"},
+	{"emails", "original_email_id", "varchar(320) NOT NULL DEFAULT ''"},
+	{"emails", "score", "tinyint NOT NULL DEFAULT '0'"},
+	{"emails", "category", "varchar(10) NOT NULL DEFAULT ''"},
+	{"emails", "matched_list", "varchar(20) NOT NULL DEFAULT ''"},
+	{"email_rechecks", "helo_name", "varchar(255) NOT NULL DEFAULT ''"},
+	{"email_rechecks", "mail_from", "varchar(320) NOT NULL DEFAULT ''"},
+	{"email_rechecks", "probe_username", "varchar(64) NOT NULL DEFAULT ''"},
+	{"email_rechecks", "user_id", "varchar(255) NOT NULL DEFAULT ''"},
+	{"email_rechecks", "gravatar", "tinyint NOT NULL DEFAULT '0'"},
+}
+
+func hasColumn(ctx context.Context, db *sql.DB, c column) (bool, error) {
+	query := `
+	select count(*)
+	from information_schema.columns
+	where table_schema = database() and table_name = ? and column_name = ?`
+
+	var count int
+
+	if err := db.QueryRowContext(ctx, query, c.table, c.name).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func Migrate(db *sql.DB) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancelfunc()
+
+	// proxy_health used to be keyed by host and port, which mixed up
+	// proxies with different credentials. Its counts are rebuilt by the
+	// next runs, so it is dropped rather than converted.
+	ok, err := hasColumn(ctx, db, column{"proxy_health", "proxy_id", ""})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if _, err := db.ExecContext(ctx, `DROP TABLE IF EXISTS proxy_health`); err != nil {
+			return err
+		}
+	}
+
+	for _, q := range migrations {
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range columns {
+		ok, err := hasColumn(ctx, db, c)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			continue
+		}
+
+		q := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.def)
+
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,13 +2,15 @@ module email_verify
 
 go 1.23.3
 
-require github.com/go-sql-driver/mysql v1.9.2
+require (
+	github.com/AfterShip/email-verifier v1.4.1
+	github.com/go-sql-driver/mysql v1.9.2
+	github.com/gorilla/websocket v1.5.3
+	golang.org/x/net v0.41.0
+)
 
 require (
 	filippo.io/edwards25519 v1.1.0 // indirect
-	github.com/AfterShip/email-verifier v1.4.1 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/hbollon/go-edlib v1.6.0 // indirect
-	golang.org/x/net v0.41.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
 )
@@ -10,9 +10,13 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"email_verify/dbconn"
+	"email_verify/proxycheck"
 	"email_verify/webroutes"
 	"email_verify/respond"
+	"email_verify/simenv"
+	"email_verify/verifier"
 )
 
 func HeaderMiddleware(headers map[string]string) func(http.Handler) http.Handler {
@@ -53,9 +57,43 @@ func main() {
 	isProdDBFlag := flag.Bool("prod-db", false, "run production db")
 	isProdModeFlag := flag.Bool("prod-mode", false, "run in production mode with static file serve")
 	portFlag := flag.String("port", "", "specify port. default: 8000")
+	autoResumeFlag := flag.Bool("auto-resume", false, "resume verification runs interrupted by a restart")
+	checkerFlag := flag.String("checker", verifier.AFTERSHIP_CHECKER, "default verification backend: " + strings.Join(verifier.CheckerNames(), ", "))
+	domainCacheTTLFlag := flag.Duration("domain-cache-ttl", verifier.DEFAULT_DOMAIN_CACHE_TTL, "how long domain checks are cached. 0 disables the cache")
+	proxyProbeSMTPFlag := flag.String("proxy-probe-smtp", proxycheck.DefaultTarget.SMTPAddr, "smtp host:port proxies are tested against")
+	proxyProbeIpFlag := flag.String("proxy-probe-ip-url", proxycheck.DefaultTarget.ExitIpURL, "url that echoes the ip a proxy exits from")
+	maxRunningFlag := flag.Int("max-running-jobs", verifier.DEFAULT_MAX_RUNNING, "how many verification jobs run at once")
+	simEnvFlag := flag.String("sim-env", "", "config file of a simulated smtp/dns environment. adds the \"" + verifier.SIMULATED_CHECKER + "\" checker that verifies against it offline")
 
 	flag.Parse()
 
+	verifier.VerifierManager.SetMaxRunning(*maxRunningFlag)
+
+	proxycheck.DefaultTarget.SMTPAddr = *proxyProbeSMTPFlag
+	proxycheck.DefaultTarget.ExitIpURL = *proxyProbeIpFlag
+
+	verifier.DomainCache.SetTTL(*domainCacheTTLFlag)
+
+	if *simEnvFlag != "" {
+		env, err := simenv.StartFromFile(*simEnvFlag)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer env.Close()
+
+		verifier.RegisterSimulatedChecker(env.ProxyURI(), env.Resolver())
+		fmt.Println("simulated environment: smtp", env.SMTPAddr(), "dns", env.DNSAddr(), "proxy", env.ProxyAddr())
+		if uris := env.ProxyURIs(); len(uris) > 0 {
+			fmt.Println("simulated proxies:", strings.Join(uris, " "))
+		}
+	}
+
+	if err := verifier.SetDefaultChecker(*checkerFlag); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
 	if *portFlag != "" {
 		ADDR = ":" + *portFlag
 	}
@@ -72,6 +110,17 @@ func main() {
 		return
 	}
 
+	if err := dbconn.Migrate(db); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if err := verifier.RestoreInterruptedRuns(db, *autoResumeFlag); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	verifier.RecheckQueue.Start(db)
+
 	webMux := webroutes.NewWebRoutesMux(db)
 	mainMux := http.NewServeMux()
 
@@ -0,0 +1,160 @@
+package proxycheck
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// Target is what a proxy is tested against. Point it at a local SMTP
+// stand-in and ip echo server to test without reaching the internet.
+type Target struct {
+	SMTPAddr string `json:"smtpAddr"`
+	ExitIpURL string `json:"exitIpUrl"`
+	TimeoutMs int `json:"timeoutMs"`
+}
+
+var DefaultTarget = Target{
+	SMTPAddr: "gmail-smtp-in.l.google.com:25",
+	ExitIpURL: "http://api.ipify.org",
+	TimeoutMs: 10000,
+}
+
+// WithDefaults fills the empty fields of t from DefaultTarget.
+func (t Target) WithDefaults() Target {
+	if t.SMTPAddr == "" {
+		t.SMTPAddr = DefaultTarget.SMTPAddr
+	}
+	if t.ExitIpURL == "" {
+		t.ExitIpURL = DefaultTarget.ExitIpURL
+	}
+	if t.TimeoutMs <= 0 {
+		t.TimeoutMs = DefaultTarget.TimeoutMs
+	}
+	return t
+}
+
+type Result struct {
+	IsReachable bool `json:"isReachable"`
+	LatencyMs int64 `json:"latencyMs"`
+	IsAuthOk bool `json:"isAuthOk"`
+	IsSMTPReachable bool `json:"isSMTPReachable"`
+	SMTPBanner string `json:"smtpBanner"`
+	ExitIp string `json:"exitIp"`
+	Error string `json:"error"`
+}
+
+func isAuthErr(err error) bool {
+	e := strings.ToLower(err.Error())
+	return strings.Contains(e, "authentication") || strings.Contains(e, "auth failed")
+}
+
+// Check connects to the proxy at proxyURI, dials the SMTP target through
+// it and asks the ip echo target for the exit ip. A failing step is
+// reported in Error and the remaining steps are skipped.
+func Check(proxyURI string, t Target) Result {
+	t = t.WithDefaults()
+	timeout := time.Duration(t.TimeoutMs) * time.Millisecond
+	res := Result{}
+
+	u, err := url.Parse(proxyURI)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", u.Host, timeout)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.LatencyMs = time.Since(start).Milliseconds()
+	res.IsReachable = true
+	conn.Close()
+
+	dialer, err := proxy.FromURL(u, &net.Dialer{Timeout: timeout})
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	ctxDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		res.Error = "proxy dialer doesn't support timeouts"
+		return res
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	smtpConn, err := ctxDialer.DialContext(ctx, "tcp", t.SMTPAddr)
+	if err != nil {
+		// the proxy only answers the connect request once the client is
+		// authenticated, so anything but an auth error means auth passed.
+		res.IsAuthOk = !isAuthErr(err)
+		res.Error = err.Error()
+		return res
+	}
+
+	res.IsAuthOk = true
+	res.IsSMTPReachable = true
+
+	smtpConn.SetDeadline(time.Now().Add(timeout))
+	banner, err := bufio.NewReader(smtpConn).ReadString('\n')
+	smtpConn.Close()
+
+	if err != nil {
+		res.IsSMTPReachable = false
+		res.Error = err.Error()
+		return res
+	}
+	res.SMTPBanner = strings.TrimSpace(banner)
+
+	exitIp, err := getExitIp(ctx, ctxDialer, t.ExitIpURL)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.ExitIp = exitIp
+
+	return res
+}
+
+func getExitIp(ctx context.Context, dialer proxy.ContextDialer, exitIpURL string) (string, error) {
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, exitIpURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("exit ip request failed: " + resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
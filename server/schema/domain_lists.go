@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	LIST_DISPOSABLE = "disposable"
+	LIST_BLOCKED = "blocked"
+	LIST_TRUSTED = "trusted"
+)
+
+// DomainListEntry puts a domain, and its subdomains, on one of the lists
+// of a user. Emails on a listed domain are settled by the list instead of
+// being checked.
+type DomainListEntry struct {
+	Id int64 `json:"id"`
+	UserId string `json:"userId"`
+	List string `json:"list"`
+	Domain string `json:"domain"`
+	CreatedDateTime string `json:"createdDateTime"`
+}
+
+func IsDomainList(list string) bool {
+	switch list {
+	case LIST_DISPOSABLE, LIST_BLOCKED, LIST_TRUSTED:
+		return true
+	}
+	return false
+}
+
+// NormalizeDomain lowercases domain and drops the "@" or "*." it may be
+// written with.
+func NormalizeDomain(domain string) (string, error) {
+	d := strings.ToLower(strings.TrimSpace(domain))
+	d = strings.TrimPrefix(d, "@")
+	d = strings.TrimPrefix(d, "*.")
+	d = strings.TrimSuffix(d, ".")
+
+	if d == "" || len(d) > 253 || !strings.Contains(d, ".") || strings.ContainsAny(d, "@ \t/\\,;") {
+		return "", errors.New("invalid domain: " + domain)
+	}
+
+	return d, nil
+}
+
+func (e *DomainListEntry) Validate() error {
+	if !IsDomainList(e.List) {
+		return errors.New("unknown list: " + e.List)
+	}
+
+	d, err := NormalizeDomain(e.Domain)
+	if err != nil {
+		return err
+	}
+	e.Domain = d
+
+	return nil
+}
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -18,6 +19,52 @@ type EmailDetails struct {
 	IsCatchAll bool `json:"isCatchAll"`
 	IsInboxFull bool `json:"isInboxFull"`
 	ErrorMsg sql.NullString `json:"errorMsg"`
+	ErrorCode string `json:"errorCode"`
+	IsRoleAccount bool `json:"isRoleAccount"`
+	IsFree bool `json:"isFree"`
+	Suggestion string `json:"suggestion"`
+	HasGravatar bool `json:"hasGravatar"`
+	GravatarUrl string `json:"gravatarUrl"`
+	VerifiedAt sql.NullString `json:"verifiedAt"`
+	OriginalEmailId string `json:"originalEmailId"`
+	Score int `json:"score"`
+	Category string `json:"category"`
+	MatchedList string `json:"matchedList"`
+}
+
+const (
+	OUTCOME_UNKNOWN = "unknown"
+	OUTCOME_CATCH_ALL = "catch-all"
+	OUTCOME_UNDELIVERABLE = "undeliverable"
+	OUTCOME_ERRORED = "errored"
+)
+
+// ReverifyOptions picks already verified emails to check again. Emails
+// verified more than OlderThanDays ago and, if Outcomes is set, with one
+// of the outcomes are picked. The zero value picks none.
+type ReverifyOptions struct {
+	OlderThanDays int `json:"olderThanDays"`
+	Outcomes []string `json:"outcomes"`
+}
+
+func (o *ReverifyOptions) IsSet() bool {
+	return o.OlderThanDays > 0 || len(o.Outcomes) > 0
+}
+
+func (o *ReverifyOptions) Validate() error {
+	if o.OlderThanDays < 0 {
+		return errors.New("reverify.olderThanDays can't be negative.")
+	}
+
+	for _, outcome := range o.Outcomes {
+		switch outcome {
+		case OUTCOME_UNKNOWN, OUTCOME_CATCH_ALL, OUTCOME_UNDELIVERABLE, OUTCOME_ERRORED:
+		default:
+			return errors.New("unknown reverify outcome: " + outcome)
+		}
+	}
+
+	return nil
 }
 
 func NewEmailDetails() EmailDetails {
@@ -35,7 +82,7 @@ func (e *EmailDetails) ToCSV() string {
 	}
 
 	return fmt.Sprintf(
-		`"%d","%s","%d","%s","%d","%d","%d","%d","%d","%d","%s"`,
+		`"%d","%s","%d","%s","%d","%d","%d","%d","%d","%d","%s","%s","%d","%d","%s","%d","%s","%d","%s","%s"`,
 		e.FileId,
 		strings.ReplaceAll(e.EmailId, `"`, `""`),
 		boolToInt(e.IsValidSyntax),
@@ -47,6 +94,15 @@ func (e *EmailDetails) ToCSV() string {
 		boolToInt(e.IsCatchAll),
 		boolToInt(e.IsInboxFull),
 		strings.ReplaceAll(em, `"`, `""`),
+		e.ErrorCode,
+		boolToInt(e.IsRoleAccount),
+		boolToInt(e.IsFree),
+		strings.ReplaceAll(e.Suggestion, `"`, `""`),
+		boolToInt(e.HasGravatar),
+		strings.ReplaceAll(e.GravatarUrl, `"`, `""`),
+		e.Score,
+		e.Category,
+		e.MatchedList,
 	)
 }
 
@@ -58,27 +114,5 @@ func boolToInt(b bool) int {
 }
 
 func (e *EmailDetails) ToCSVLn() string {
-	em := ""
-
-	if !e.ErrorMsg.Valid {
-		em = "NULL"
-	} else {
-		em = e.ErrorMsg.String
-	}
-
-	return fmt.Sprintf(
-		`"%d","%s","%d","%s","%d","%d","%d","%d","%d","%d","%s"
-`,
-		e.FileId,
-		strings.ReplaceAll(e.EmailId, `"`, `""`),
-		boolToInt(e.IsValidSyntax),
-		e.Reachable,
-		boolToInt(e.IsDeliverable),
-		boolToInt(e.IsHostExists),
-		boolToInt(e.HasMxRecords),
-		boolToInt(e.IsDisposable),
-		boolToInt(e.IsCatchAll),
-		boolToInt(e.IsInboxFull),
-		strings.ReplaceAll(em, `"`, `""`),
-	)
+	return e.ToCSV() + "\n"
 }
@@ -20,4 +20,10 @@ type FileStats struct {
 	InboxFull int64 `json:"inboxFull"`
 	HostExists int64 `json:"hostExists"`
 	Errored int64 `json:"errored"`
+	Categories CategoryStats `json:"categories"`
+}
+
+type ErrorCodeCount struct {
+	ErrorCode string `json:"errorCode"`
+	Count int64 `json:"count"`
 }
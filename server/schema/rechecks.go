@@ -0,0 +1,22 @@
+package schema
+
+// EmailRecheck is an email that got a greylisting reply and is checked
+// again once DueDateTime has passed.
+type EmailRecheck struct {
+	FileId int64 `json:"fileId"`
+	EmailId string `json:"emailId"`
+	UserId string `json:"userId"`
+	Checker string `json:"checker"`
+	Proxy string `json:"-"`
+	// SMTPIdentity is the one the email was first checked with, as
+	// greylisting only lets the same sender through.
+	SMTPIdentity SMTPIdentity `json:"smtpIdentity"`
+	// Gravatar is whether the run looked up gravatars, so the recheck
+	// doesn't clear the ones it found.
+	Gravatar bool `json:"gravatar"`
+	Attempts int `json:"attempts"`
+	MaxAttempts int `json:"maxAttempts"`
+	DelayMs int `json:"delayMs"`
+	LastError string `json:"lastError"`
+	DueDateTime string `json:"dueDateTime"`
+}
@@ -0,0 +1,8 @@
+package schema
+
+type VerifierRun struct {
+	FileId int64 `json:"fileId"`
+	State string `json:"state"`
+	Data []byte `json:"data"`
+	UpdatedDateTime string `json:"updatedDateTime"`
+}
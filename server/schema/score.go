@@ -0,0 +1,68 @@
+package schema
+
+import "errors"
+
+const (
+	CATEGORY_SAFE = "safe"
+	CATEGORY_RISKY = "risky"
+	CATEGORY_INVALID = "invalid"
+	CATEGORY_UNKNOWN = "unknown"
+)
+
+var Categories = []string{
+	CATEGORY_SAFE,
+	CATEGORY_RISKY,
+	CATEGORY_INVALID,
+	CATEGORY_UNKNOWN,
+}
+
+// ScoreWeights is how a user scores verified emails. A deliverable email
+// starts at 100 and loses the penalty of each trait it has; an email that
+// couldn't be verified starts at Unknown instead. Scores of at least
+// SafeThreshold are safe, the others risky.
+type ScoreWeights struct {
+	CatchAll int `json:"catchAll"`
+	Disposable int `json:"disposable"`
+	RoleAccount int `json:"roleAccount"`
+	Free int `json:"free"`
+	InboxFull int `json:"inboxFull"`
+	Gravatar int `json:"gravatar"`
+	Unknown int `json:"unknown"`
+	SafeThreshold int `json:"safeThreshold"`
+}
+
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		CatchAll: 40,
+		Disposable: 60,
+		RoleAccount: 20,
+		Free: 5,
+		InboxFull: 50,
+		Gravatar: 5,
+		Unknown: 50,
+		SafeThreshold: 80,
+	}
+}
+
+func (w *ScoreWeights) Validate() error {
+	for _, n := range []int{w.CatchAll, w.Disposable, w.RoleAccount, w.Free, w.InboxFull, w.Gravatar, w.Unknown} {
+		if n < 0 || n > 100 {
+			return errors.New("score weights must be between 0 and 100.")
+		}
+	}
+
+	if w.SafeThreshold < 1 || w.SafeThreshold > 100 {
+		return errors.New("safeThreshold must be between 1 and 100.")
+	}
+
+	return nil
+}
+
+// CategoryStats counts the emails of a file per category.
+type CategoryStats struct {
+	Safe int64 `json:"safe"`
+	Risky int64 `json:"risky"`
+	Invalid int64 `json:"invalid"`
+	Unknown int64 `json:"unknown"`
+	AverageScore float64 `json:"averageScore"`
+}
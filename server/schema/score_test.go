@@ -0,0 +1,33 @@
+package schema
+
+import "testing"
+
+func TestScoreWeightsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		change func(w *ScoreWeights)
+		ok bool
+	}{
+		{name: "defaults", change: func(w *ScoreWeights) {}, ok: true},
+		{name: "zero penalty", change: func(w *ScoreWeights) { w.CatchAll = 0 }, ok: true},
+		{name: "full penalty", change: func(w *ScoreWeights) { w.Disposable = 100 }, ok: true},
+		{name: "negative penalty", change: func(w *ScoreWeights) { w.RoleAccount = -1 }},
+		{name: "penalty over 100", change: func(w *ScoreWeights) { w.InboxFull = 101 }},
+		{name: "gravatar over 100", change: func(w *ScoreWeights) { w.Gravatar = 101 }},
+		{name: "unknown over 100", change: func(w *ScoreWeights) { w.Unknown = 101 }},
+		{name: "threshold of 1", change: func(w *ScoreWeights) { w.SafeThreshold = 1 }, ok: true},
+		{name: "threshold of 0", change: func(w *ScoreWeights) { w.SafeThreshold = 0 }},
+		{name: "threshold over 100", change: func(w *ScoreWeights) { w.SafeThreshold = 101 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := DefaultScoreWeights()
+			tt.change(&w)
+
+			if err := w.Validate(); (err == nil) != tt.ok {
+				t.Fatalf("Validate returned %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
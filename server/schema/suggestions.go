@@ -0,0 +1,22 @@
+package schema
+
+// EmailSuggestion is an email whose domain looks like a typo of
+// Suggestion. CorrectedEmailId is the email with the suggested domain.
+type EmailSuggestion struct {
+	EmailId string `json:"emailId"`
+	Suggestion string `json:"suggestion"`
+	CorrectedEmailId string `json:"correctedEmailId"`
+}
+
+// AcceptedSuggestion is an email rewritten to its corrected address.
+type AcceptedSuggestion struct {
+	OriginalEmailId string `json:"originalEmailId"`
+	EmailId string `json:"emailId"`
+}
+
+// SkippedSuggestion is a suggestion that could not be accepted, like one
+// whose corrected address is already in the file.
+type SkippedSuggestion struct {
+	EmailId string `json:"emailId"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+// SMTPIdentity is what the verifier introduces itself with to mail
+// servers. Empty fields fall back to the next level: the run, then the
+// user profile, then the server defaults. An empty ProbeUsername probes
+// catch-all domains with a made-up username, the same for each domain.
+type SMTPIdentity struct {
+	HeloName string `json:"heloName"`
+	FromEmail string `json:"fromEmail"`
+	ProbeUsername string `json:"probeUsername"`
+}
+
+func (i *SMTPIdentity) Validate() error {
+	i.HeloName = strings.TrimSpace(i.HeloName)
+	i.FromEmail = strings.TrimSpace(i.FromEmail)
+	i.ProbeUsername = strings.TrimSpace(i.ProbeUsername)
+
+	if i.HeloName != "" && (len(i.HeloName) > 255 || strings.ContainsAny(i.HeloName, " \t\r\n@")) {
+		return errors.New("invalid heloName: " + i.HeloName)
+	}
+
+	if i.FromEmail != "" {
+		a, err := mail.ParseAddress(i.FromEmail)
+		if err != nil || a.Address != i.FromEmail {
+			return errors.New("invalid fromEmail: " + i.FromEmail)
+		}
+	}
+
+	if i.ProbeUsername != "" && (len(i.ProbeUsername) > 64 || strings.ContainsAny(i.ProbeUsername, " \t\r\n@<>")) {
+		return errors.New("invalid probeUsername: " + i.ProbeUsername)
+	}
+
+	return nil
+}
+
+// Override returns i with the fields set in o replaced by them.
+func (i SMTPIdentity) Override(o SMTPIdentity) SMTPIdentity {
+	if o.HeloName != "" {
+		i.HeloName = o.HeloName
+	}
+	if o.FromEmail != "" {
+		i.FromEmail = o.FromEmail
+	}
+	if o.ProbeUsername != "" {
+		i.ProbeUsername = o.ProbeUsername
+	}
+	return i
+}
+
+type UserProfile struct {
+	UserId string `json:"userId"`
+	SMTPIdentity SMTPIdentity `json:"smtpIdentity"`
+}
@@ -0,0 +1,125 @@
+package simenv
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Config describes the simulated mail world. Every domain that isn't
+// listed doesn't exist.
+type Config struct {
+	// addresses the servers listen on. An empty address picks a free
+	// port on 127.0.0.1.
+	SMTPAddr string `json:"smtpAddr"`
+	DNSAddr string `json:"dnsAddr"`
+	ProxyAddr string `json:"proxyAddr"`
+
+	// more proxies in front of the fake SMTP server, for runs that rotate
+	// through several.
+	Proxies []ProxyConfig `json:"proxies"`
+
+	Domains map[string]DomainConfig `json:"domains"`
+}
+
+// ProxyConfig scripts one of the extra proxies.
+type ProxyConfig struct {
+	Addr string `json:"addr"`
+	// the proxy accepts connections but fails every SOCKS handshake.
+	Down bool `json:"down"`
+}
+
+// DomainConfig scripts how the mail server of a domain behaves.
+type DomainConfig struct {
+	// MX hosts of the domain. Defaults to mx.<domain>.
+	Mx []string `json:"mx"`
+	// the domain exists but has no MX records.
+	NoMx bool `json:"noMx"`
+
+	// wait before sending the banner, to simulate slow servers.
+	BannerDelayMs int `json:"bannerDelayMs"`
+	// reply code of the banner. Anything but 220 refuses the connection,
+	// e.g. 421 or 554 for a blocked client.
+	BannerCode int `json:"bannerCode"`
+
+	// every RCPT TO is accepted.
+	CatchAll bool `json:"catchAll"`
+	// reply codes for RCPT TO per username, e.g. {"alice": 250,
+	// "full": 452, "gone": 550}.
+	Users map[string]int `json:"users"`
+	// reply code for usernames that aren't listed. Defaults to 550.
+	DefaultCode int `json:"defaultCode"`
+
+	// answer the first RCPT TO of a recipient with 451 and only accept
+	// retries after GreylistSeconds.
+	GreylistSeconds int `json:"greylistSeconds"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (cfg *Config) normalize() error {
+	domains := make(map[string]DomainConfig, len(cfg.Domains))
+
+	for name, d := range cfg.Domains {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == "" {
+			return errors.New("domain name can't be empty.")
+		}
+
+		if d.BannerCode == 0 {
+			d.BannerCode = 220
+		}
+
+		if d.DefaultCode == 0 {
+			d.DefaultCode = 550
+		}
+
+		if len(d.Mx) == 0 && !d.NoMx {
+			d.Mx = []string{"mx." + name}
+		}
+
+		for i := range d.Mx {
+			d.Mx[i] = strings.ToLower(strings.TrimSuffix(d.Mx[i], "."))
+		}
+
+		domains[name] = d
+	}
+
+	cfg.Domains = domains
+
+	return nil
+}
+
+// domainOfMx returns the domain that host is an MX host of.
+func (cfg *Config) domainOfMx(host string) (string, bool) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	for name, d := range cfg.Domains {
+		for _, mx := range d.Mx {
+			if mx == host {
+				return name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// isMxHost reports whether host is the MX host of any domain.
+func (cfg *Config) isMxHost(host string) bool {
+	_, ok := cfg.domainOfMx(host)
+	return ok
+}
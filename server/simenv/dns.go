@@ -0,0 +1,119 @@
+package simenv
+
+import (
+	"net"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsServer answers MX and A queries for the configured domains over UDP.
+// MX hosts resolve to 127.0.0.1, everything else is NXDOMAIN.
+type dnsServer struct {
+	cfg  *Config
+	conn net.PacketConn
+}
+
+func startDNS(cfg *Config) (*dnsServer, error) {
+	addr := cfg.DNSAddr
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &dnsServer{cfg: cfg, conn: conn}
+	go s.serve()
+
+	return s, nil
+}
+
+func (s *dnsServer) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *dnsServer) Close() error {
+	return s.conn.Close()
+}
+
+func (s *dnsServer) serve() {
+	buf := make([]byte, 512)
+
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		res, err := s.answer(buf[:n])
+		if err != nil {
+			continue
+		}
+
+		s.conn.WriteTo(res, addr)
+	}
+}
+
+func (s *dnsServer) answer(req []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+
+	h, err := p.Start(req)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	h.Response = true
+	h.Authoritative = true
+	h.RCode = dnsmessage.RCodeSuccess
+
+	name := strings.ToLower(strings.TrimSuffix(q.Name.String(), "."))
+	d, isDomain := s.cfg.Domains[name]
+	isMx := s.cfg.isMxHost(name)
+
+	if !isDomain && !isMx {
+		h.RCode = dnsmessage.RCodeNameError
+	}
+
+	b := dnsmessage.NewBuilder(nil, h)
+	b.EnableCompression()
+
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	rh := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60}
+
+	switch {
+	case q.Type == dnsmessage.TypeMX && isDomain:
+		for i, mx := range d.Mx {
+			host, err := dnsmessage.NewName(mx + ".")
+			if err != nil {
+				return nil, err
+			}
+
+			if err := b.MXResource(rh, dnsmessage.MXResource{Pref: uint16(10 * (i + 1)), MX: host}); err != nil {
+				return nil, err
+			}
+		}
+	case q.Type == dnsmessage.TypeA && isMx:
+		if err := b.AResource(rh, dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}}); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Finish()
+}
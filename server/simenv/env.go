@@ -0,0 +1,123 @@
+// Package simenv runs a simulated mail world on localhost: a stub DNS
+// server, a fake SMTP server and a SOCKS5 proxy in front of it, with the
+// behaviour of each domain scripted from a config file. It lets the real
+// AfterShip checker run offline, for end-to-end tests and demos.
+package simenv
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+type Env struct {
+	cfg   *Config
+	dns   *dnsServer
+	smtp  *smtpServer
+	proxy *socksServer
+	proxies []*socksServer
+}
+
+// Start starts the servers of cfg.
+func Start(cfg *Config) (*Env, error) {
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+
+	e := &Env{cfg: cfg}
+
+	var err error
+
+	if e.dns, err = startDNS(cfg); err != nil {
+		e.Close()
+		return nil, err
+	}
+
+	if e.smtp, err = startSMTP(cfg); err != nil {
+		e.Close()
+		return nil, err
+	}
+
+	if e.proxy, err = startSocks(cfg.ProxyAddr, false, e.smtp); err != nil {
+		e.Close()
+		return nil, err
+	}
+
+	for _, p := range cfg.Proxies {
+		s, err := startSocks(p.Addr, p.Down, e.smtp)
+		if err != nil {
+			e.Close()
+			return nil, err
+		}
+		e.proxies = append(e.proxies, s)
+	}
+
+	return e, nil
+}
+
+// StartFromFile loads the config at path and starts its servers.
+func StartFromFile(path string) (*Env, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Start(cfg)
+}
+
+func (e *Env) DNSAddr() string {
+	return e.dns.Addr()
+}
+
+func (e *Env) SMTPAddr() string {
+	return e.smtp.Addr()
+}
+
+func (e *Env) ProxyAddr() string {
+	return e.proxy.Addr()
+}
+
+// ProxyURI is the proxy to verify through so that SMTP connections to
+// the simulated MX hosts reach the fake SMTP server.
+func (e *Env) ProxyURI() string {
+	return e.proxy.URI()
+}
+
+// ProxyURIs are the extra proxies of the config, in its order. Runs of
+// the simulated checker can rotate through them.
+func (e *Env) ProxyURIs() []string {
+	uris := make([]string, len(e.proxies))
+	for i, p := range e.proxies {
+		uris[i] = p.URI()
+	}
+	return uris
+}
+
+// Resolver returns a resolver that asks only the stub DNS server. The
+// simulated checker looks its MX records up with it.
+func (e *Env) Resolver() *net.Resolver {
+	addr := e.DNSAddr()
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: 5 * time.Second}
+			return d.DialContext(ctx, "udp", addr)
+		},
+	}
+}
+
+func (e *Env) Close() {
+	for _, p := range e.proxies {
+		p.Close()
+	}
+	if e.proxy != nil {
+		e.proxy.Close()
+	}
+	if e.smtp != nil {
+		e.smtp.Close()
+	}
+	if e.dns != nil {
+		e.dns.Close()
+	}
+}
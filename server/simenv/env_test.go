@@ -0,0 +1,247 @@
+package simenv_test
+
+import (
+	"email_verify/schema"
+	"email_verify/simenv"
+	"email_verify/verifier"
+	"net"
+	"testing"
+	"time"
+
+	emailverifier "github.com/AfterShip/email-verifier"
+)
+
+// TestSimulatedChecker runs the simulated checker against the scenarios
+// of example.json.
+func TestSimulatedChecker(t *testing.T) {
+	resolver := net.DefaultResolver
+
+	env, err := simenv.StartFromFile("example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(env.Close)
+
+	verifier.RegisterSimulatedChecker(env.ProxyURI(), env.Resolver())
+
+	if net.DefaultResolver != resolver {
+		t.Fatal("the resolver of the process was replaced")
+	}
+
+	tests := []struct {
+		name string
+		email string
+		code verifier.ErrorCode
+		reachable string
+		deliverable bool
+		catchAll bool
+		fullInbox bool
+	}{
+		{name: "250", email: "alice@sim-ok.test", reachable: "yes", deliverable: true},
+		{name: "550", email: "nobody@sim-ok.test", reachable: "no"},
+		{name: "452", email: "full@sim-ok.test", reachable: "no", fullInbox: true},
+		{name: "catch-all", email: "anyone@sim-catchall.test", reachable: "unknown", catchAll: true},
+		{name: "greylisting", email: "carol@sim-greylist.test", code: verifier.ERR_GREYLISTED},
+		{name: "slow banner", email: "dave@sim-slow.test", code: verifier.ERR_CONNECT_TIMEOUT},
+		{name: "blocked", email: "erin@sim-blocked.test", code: verifier.ERR_BLOCKED},
+		{name: "no mx", email: "frank@sim-nomx.test", code: verifier.ERR_DNS},
+	}
+
+	c, err := verifier.NewChecker(verifier.SIMULATED_CHECKER, verifier.CheckerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the host limiter gets the simulated MX hosts too.
+	hosts, err := c.(verifier.MxResolver).MxHosts("sim-ok.test")
+	if err != nil || len(hosts) != 1 || hosts[0] != "mx.sim-ok.test" {
+		t.Fatalf("MX hosts are %v (err: %v), want mx.sim-ok.test", hosts, err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := verifier.NewChecker(verifier.SIMULATED_CHECKER, verifier.CheckerOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ret, err := c.Verify(tt.email)
+
+			code := verifier.ERR_NONE
+			if err != nil {
+				code = verifier.ClassifyError(err)
+			}
+
+			if code != tt.code {
+				t.Fatalf("error code is %q, want %q (err: %v)", code, tt.code, err)
+			}
+
+			if tt.code != verifier.ERR_NONE {
+				return
+			}
+
+			if ret == nil || ret.SMTP == nil {
+				t.Fatal("no SMTP result")
+			}
+
+			if ret.Reachable != tt.reachable {
+				t.Errorf("reachable is %q, want %q", ret.Reachable, tt.reachable)
+			}
+
+			if ret.SMTP.Deliverable != tt.deliverable {
+				t.Errorf("deliverable is %v, want %v", ret.SMTP.Deliverable, tt.deliverable)
+			}
+
+			if ret.SMTP.CatchAll != tt.catchAll {
+				t.Errorf("catch-all is %v, want %v", ret.SMTP.CatchAll, tt.catchAll)
+			}
+
+			if ret.SMTP.FullInbox != tt.fullInbox {
+				t.Errorf("full inbox is %v, want %v", ret.SMTP.FullInbox, tt.fullInbox)
+			}
+		})
+	}
+}
+
+// TestGreylistRetry checks that an email greylisted once is verified when
+// it is checked again after the delay.
+func TestGreylistRetry(t *testing.T) {
+	env, err := simenv.Start(&simenv.Config{
+		Domains: map[string]simenv.DomainConfig{
+			"sim-greylist.test": {GreylistSeconds: 1, Users: map[string]int{"carol": 250}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(env.Close)
+
+	verifier.RegisterSimulatedChecker(env.ProxyURI(), env.Resolver())
+
+	c, err := verifier.NewChecker(verifier.SIMULATED_CHECKER, verifier.CheckerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Verify("carol@sim-greylist.test"); err == nil || verifier.ClassifyError(err) != verifier.ERR_GREYLISTED {
+		t.Fatalf("first check returned %v, want it greylisted", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	ret, err := c.Verify("carol@sim-greylist.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ret.SMTP.Deliverable || ret.SMTP.CatchAll {
+		t.Fatalf("deliverable is %v and catch-all %v, want true and false", ret.SMTP.Deliverable, ret.SMTP.CatchAll)
+	}
+}
+
+// TestDeferredProbeNotCached checks that a catch-all domain whose probe
+// was greylisted isn't cached as a regular one when the email itself is
+// accepted.
+func TestDeferredProbeNotCached(t *testing.T) {
+	env, err := simenv.Start(&simenv.Config{
+		Domains: map[string]simenv.DomainConfig{
+			"sim-greylist.test": {GreylistSeconds: 1, CatchAll: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(env.Close)
+
+	verifier.RegisterSimulatedChecker(env.ProxyURI(), env.Resolver())
+
+	verify := func(probe string) (*emailverifier.Result, error) {
+		c, err := verifier.NewChecker(verifier.SIMULATED_CHECKER, verifier.CheckerOptions{
+			Identity: schema.SMTPIdentity{ProbeUsername: probe},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.Verify("carol@sim-greylist.test")
+	}
+
+	// greylists carol and the first probe.
+	if _, err := verify("first"); verifier.ClassifyError(err) != verifier.ERR_GREYLISTED {
+		t.Fatalf("first check returned %v, want it greylisted", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	// carol is accepted while the new probe is greylisted.
+	ret, err := verify("second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ret.SMTP.Deliverable || ret.SMTP.CatchAll {
+		t.Fatalf("deliverable is %v and catch-all %v, want true and false", ret.SMTP.Deliverable, ret.SMTP.CatchAll)
+	}
+
+	// the domain is probed again and found to be a catch-all.
+	ret, err = verify("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ret.SMTP.CatchAll {
+		t.Fatal("the catch-all domain was cached as a regular one")
+	}
+}
+
+// TestRunProxy checks that the simulated checker connects through the
+// proxy of the run and that a proxy that is down is reported as such.
+func TestRunProxy(t *testing.T) {
+	env, err := simenv.Start(&simenv.Config{
+		Proxies: []simenv.ProxyConfig{{}, {Down: true}},
+		Domains: map[string]simenv.DomainConfig{
+			"sim-ok.test": {Users: map[string]int{"alice": 250}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(env.Close)
+
+	verifier.RegisterSimulatedChecker(env.ProxyURI(), env.Resolver())
+
+	proxies := env.ProxyURIs()
+
+	tests := []struct {
+		name string
+		proxy string
+		code verifier.ErrorCode
+	}{
+		{name: "up", proxy: proxies[0]},
+		{name: "down", proxy: proxies[1], code: verifier.ERR_PROXY},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dialed []error
+
+			c, err := verifier.NewChecker(verifier.SIMULATED_CHECKER, verifier.CheckerOptions{
+				Proxy: tt.proxy,
+				ProxyDialed: func(err error) {
+					dialed = append(dialed, err)
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.Verify("alice@sim-ok.test")
+			if code := verifier.ClassifyError(err); code != tt.code {
+				t.Fatalf("error code is %q, want %q (err: %v)", code, tt.code, err)
+			}
+
+			if len(dialed) == 0 || (dialed[0] == nil) != (tt.code == verifier.ERR_NONE) {
+				t.Fatalf("the run was told %v about its proxy", dialed)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package simenv
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var replyText = map[int]string{
+	220: "sim.local ESMTP ready",
+	221: "2.0.0 Bye",
+	250: "2.1.5 OK",
+	252: "2.5.0 Cannot VRFY user",
+	421: "4.7.0 Try again later, closing connection",
+	450: "4.2.1 Mailbox temporarily unavailable",
+	451: "4.7.1 Greylisted, please try again later",
+	452: "4.2.2 Mailbox full",
+	502: "5.5.2 Command not implemented",
+	550: "5.1.1 User unknown",
+	551: "5.1.6 User not local",
+	552: "5.2.2 Mailbox full",
+	553: "5.1.3 Mailbox name not allowed",
+	554: "5.7.1 You're blocked",
+}
+
+func reply(code int) string {
+	text, ok := replyText[code]
+	if !ok {
+		text = "Reply"
+	}
+	return fmt.Sprintf("%d %s\r\n", code, text)
+}
+
+// smtpServer speaks enough SMTP for a verification probe: the banner,
+// HELO/EHLO, MAIL FROM, RCPT TO, RSET, NOOP and QUIT. Replies to RCPT TO
+// follow the DomainConfig of the recipient's domain.
+type smtpServer struct {
+	cfg *Config
+	ln  net.Listener
+
+	// first RCPT TO of each greylisted recipient
+	greylist map[string]time.Time
+	mu       sync.Mutex
+}
+
+func startSMTP(cfg *Config) (*smtpServer, error) {
+	addr := cfg.SMTPAddr
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &smtpServer{cfg: cfg, ln: ln, greylist: make(map[string]time.Time)}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// the domain isn't known when connecting directly, so the
+			// banner uses the defaults.
+			go s.serveConn(conn, "")
+		}
+	}()
+
+	return s, nil
+}
+
+func (s *smtpServer) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *smtpServer) Close() error {
+	return s.ln.Close()
+}
+
+// serveConn runs one SMTP session. domain is the domain whose MX host the
+// client meant to reach, if known.
+func (s *smtpServer) serveConn(conn net.Conn, domain string) {
+	defer conn.Close()
+
+	if d, ok := s.cfg.Domains[domain]; ok {
+		if d.BannerDelayMs > 0 {
+			time.Sleep(time.Duration(d.BannerDelayMs) * time.Millisecond)
+		}
+
+		if d.BannerCode != 220 {
+			conn.Write([]byte(reply(d.BannerCode)))
+			return
+		}
+	}
+
+	conn.Write([]byte(reply(220)))
+
+	r := bufio.NewReader(conn)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		cmd, arg, _ := strings.Cut(line, " ")
+
+		var res string
+
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			res = "250 sim.local\r\n"
+		case "MAIL", "RSET", "NOOP":
+			res = "250 2.0.0 OK\r\n"
+		case "RCPT":
+			res = reply(s.rcpt(arg))
+		case "VRFY":
+			res = reply(252)
+		case "QUIT":
+			conn.Write([]byte(reply(221)))
+			return
+		default:
+			res = reply(502)
+		}
+
+		if _, err := conn.Write([]byte(res)); err != nil {
+			return
+		}
+	}
+}
+
+// rcpt returns the reply code for "TO:<user@domain>".
+func (s *smtpServer) rcpt(arg string) int {
+	addr := strings.TrimSpace(arg)
+	addr = strings.TrimPrefix(strings.TrimPrefix(addr, "TO:"), "to:")
+	addr = strings.ToLower(strings.Trim(strings.TrimSpace(addr), "<>"))
+
+	user, domain, ok := strings.Cut(addr, "@")
+	if !ok {
+		return 553
+	}
+
+	d, ok := s.cfg.Domains[domain]
+	if !ok {
+		return 551
+	}
+
+	if d.GreylistSeconds > 0 && s.isGreylisted(addr, d.GreylistSeconds) {
+		return 451
+	}
+
+	if d.CatchAll {
+		return 250
+	}
+
+	if code, ok := d.Users[user]; ok {
+		return code
+	}
+
+	return d.DefaultCode
+}
+
+func (s *smtpServer) isGreylisted(addr string, seconds int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	first, ok := s.greylist[addr]
+	if !ok {
+		s.greylist[addr] = time.Now()
+		return true
+	}
+
+	return time.Since(first) < time.Duration(seconds) * time.Second
+}
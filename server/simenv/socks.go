@@ -0,0 +1,226 @@
+package simenv
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+)
+
+// socksServer is a SOCKS5 proxy that hands every connection to port 25 of
+// a simulated MX host to the fake SMTP server. The checker always dials
+// port 25, which usually can't be listened on, so it reaches the fake
+// server through this proxy. Credentials are accepted but not checked.
+// A proxy that is down closes every connection before the handshake.
+type socksServer struct {
+	smtp *smtpServer
+	ln   net.Listener
+	down bool
+}
+
+func startSocks(addr string, down bool, smtp *smtpServer) (*socksServer, error) {
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &socksServer{smtp: smtp, ln: ln, down: down}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn)
+		}
+	}()
+
+	return s, nil
+}
+
+func (s *socksServer) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *socksServer) Close() error {
+	return s.ln.Close()
+}
+
+const (
+	socksVersion = 5
+
+	socksNoAuth       = 0
+	socksUserPassAuth = 2
+	socksNoMethod     = 0xff
+
+	socksConnect = 1
+
+	socksAtypIPv4   = 1
+	socksAtypDomain = 3
+	socksAtypIPv6   = 4
+
+	socksSucceeded       = 0
+	socksHostUnreachable = 4
+	socksCmdNotSupported = 7
+)
+
+func (s *socksServer) serveConn(conn net.Conn) {
+	handedOff := false
+	defer func() {
+		if !handedOff {
+			conn.Close()
+		}
+	}()
+
+	if s.down {
+		return
+	}
+
+	method, err := s.negotiate(conn)
+	if err != nil || method == socksNoMethod {
+		return
+	}
+
+	if method == socksUserPassAuth {
+		if err := s.userPassAuth(conn); err != nil {
+			return
+		}
+	}
+
+	host, port, cmd, err := readSocksRequest(conn)
+	if err != nil {
+		return
+	}
+
+	if cmd != socksConnect {
+		writeSocksReply(conn, socksCmdNotSupported)
+		return
+	}
+
+	domain, ok := s.smtp.cfg.domainOfMx(host)
+	if port != 25 || (!ok && net.ParseIP(host) == nil) {
+		writeSocksReply(conn, socksHostUnreachable)
+		return
+	}
+
+	if err := writeSocksReply(conn, socksSucceeded); err != nil {
+		return
+	}
+
+	handedOff = true
+	s.smtp.serveConn(conn, domain)
+}
+
+// negotiate picks no auth, or username/password when the client only
+// offers that.
+func (s *socksServer) negotiate(conn net.Conn) (byte, error) {
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return 0, err
+	}
+
+	methods := make([]byte, head[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return 0, err
+	}
+
+	method := byte(socksNoMethod)
+	for _, m := range methods {
+		if m == socksNoAuth {
+			method = socksNoAuth
+			break
+		}
+		if m == socksUserPassAuth {
+			method = socksUserPassAuth
+		}
+	}
+
+	_, err := conn.Write([]byte{socksVersion, method})
+
+	return method, err
+}
+
+func (s *socksServer) userPassAuth(conn net.Conn) error {
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return err
+	}
+
+	user := make([]byte, head[1])
+	if _, err := io.ReadFull(conn, user); err != nil {
+		return err
+	}
+
+	passLen := make([]byte, 1)
+	if _, err := io.ReadFull(conn, passLen); err != nil {
+		return err
+	}
+
+	pass := make([]byte, passLen[0])
+	if _, err := io.ReadFull(conn, pass); err != nil {
+		return err
+	}
+
+	_, err := conn.Write([]byte{1, 0})
+
+	return err
+}
+
+func readSocksRequest(conn net.Conn) (host string, port int, cmd byte, err error) {
+	head := make([]byte, 4)
+	if _, err = io.ReadFull(conn, head); err != nil {
+		return
+	}
+
+	cmd = head[1]
+
+	switch head[3] {
+	case socksAtypIPv4:
+		ip := make([]byte, 4)
+		if _, err = io.ReadFull(conn, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	case socksAtypIPv6:
+		ip := make([]byte, 16)
+		if _, err = io.ReadFull(conn, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	case socksAtypDomain:
+		l := make([]byte, 1)
+		if _, err = io.ReadFull(conn, l); err != nil {
+			return
+		}
+		name := make([]byte, l[0])
+		if _, err = io.ReadFull(conn, name); err != nil {
+			return
+		}
+		host = string(name)
+	default:
+		err = io.ErrUnexpectedEOF
+		return
+	}
+
+	p := make([]byte, 2)
+	if _, err = io.ReadFull(conn, p); err != nil {
+		return
+	}
+	port = int(binary.BigEndian.Uint16(p))
+
+	return
+}
+
+func writeSocksReply(conn net.Conn, rep byte) error {
+	_, err := conn.Write([]byte{socksVersion, rep, 0, socksAtypIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// URI returns the proxy address in the form the checkers take.
+func (s *socksServer) URI() string {
+	return "socks5://" + s.Addr()
+}
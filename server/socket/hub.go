@@ -0,0 +1,151 @@
+package socket
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// SUBSCRIBER_BUFFER is how many events can wait to be written to a
+// subscribed socket. A socket that falls further behind is disconnected
+// so that it doesn't hold up the others.
+const SUBSCRIBER_BUFFER = 256
+
+// Message is an event waiting to be written to a socket.
+type Message struct {
+	Name string
+	Data string
+	Seq  int64
+}
+
+// subscriber writes the events queued for s in its own goroutine, so that
+// emitting never waits on a socket.
+type subscriber struct {
+	s   Socket
+	out chan Message
+}
+
+func (sub *subscriber) write() {
+	for m := range sub.out {
+		sub.s.EmitSeq(m.Name, m.Data, m.Seq)
+	}
+}
+
+// Hub sends events to every socket subscribed to a topic, such as all the
+// clients watching the same file.
+type Hub struct {
+	topics map[int64]map[Socket]*subscriber
+	mu     sync.Mutex
+}
+
+func NewHub() *Hub {
+	return &Hub{topics: make(map[int64]map[Socket]*subscriber)}
+}
+
+// Subscribe adds s to topic. The first messages are sent to s before any
+// event emitted afterwards. The returned func removes it again and is
+// meant to be called once s is disconnected.
+func (h *Hub) Subscribe(topic int64, s Socket, first ...Message) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.topics[topic]
+	if !ok {
+		subs = make(map[Socket]*subscriber)
+		h.topics[topic] = subs
+	}
+
+	if old, ok := subs[s]; ok {
+		close(old.out)
+	}
+
+	sub := &subscriber{s: s, out: make(chan Message, SUBSCRIBER_BUFFER + len(first))}
+	for _, m := range first {
+		sub.out <- m
+	}
+	subs[s] = sub
+
+	go sub.write()
+
+	return func() {
+		h.Unsubscribe(topic, s)
+	}
+}
+
+func (h *Hub) Unsubscribe(topic int64, s Socket) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(topic, s)
+}
+
+// remove takes s off topic and stops its writer. h.mu must be held.
+func (h *Hub) remove(topic int64, s Socket) {
+	subs, ok := h.topics[topic]
+	if !ok {
+		return
+	}
+
+	sub, ok := subs[s]
+	if !ok {
+		return
+	}
+
+	delete(subs, s)
+	close(sub.out)
+
+	if len(subs) == 0 {
+		delete(h.topics, topic)
+	}
+}
+
+// Count returns how many sockets are subscribed to topic.
+func (h *Hub) Count(topic int64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic])
+}
+
+// Emit queues the event for the subscribers of topic. It doesn't wait for
+// it to be written, so it can be called with other locks held.
+func (h *Hub) Emit(topic int64, ev string, data string) {
+	h.EmitSeq(topic, ev, data, 0)
+}
+
+// EmitSeq is Emit for an event numbered seq. Events are written to each
+// socket in the order they are emitted in.
+func (h *Hub) EmitSeq(topic int64, ev string, data string, seq int64) {
+	m := Message{Name: ev, Data: data, Seq: seq}
+	slow := []Socket{}
+
+	h.mu.Lock()
+	for s, sub := range h.topics[topic] {
+		select {
+		case sub.out <- m:
+		default:
+			slow = append(slow, s)
+		}
+	}
+
+	for _, s := range slow {
+		h.remove(topic, s)
+	}
+	h.mu.Unlock()
+
+	// closing the socket ends its Listen, which lets its owner clean up
+	// after it.
+	for _, s := range slow {
+		s.Close()
+	}
+}
+
+// EmitHub marshals obj and sends it to the subscribers of topic.
+func EmitHub[T any](h *Hub, topic int64, ev string, obj T) {
+	o, err := json.Marshal(obj)
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	h.Emit(topic, ev, string(o))
+}
@@ -5,10 +5,16 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// WRITE_TIMEOUT is how long an event may take to be written. A client
+// that can't keep up is disconnected.
+const WRITE_TIMEOUT = 10 * time.Second
+
 var upgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 	ReadBufferSize:  1024,
@@ -24,6 +30,7 @@ type wsocket struct {
 	isAuth     bool
 	eventMap   map[string]func([]byte)
 	onceEvents map[string]struct{}
+	writeMu    sync.Mutex
 }
 
 type Socket interface {
@@ -31,6 +38,7 @@ type Socket interface {
 	Once(string, func([]byte))
 	Close()
 	Emit(string, string)
+	EmitSeq(string, string, int64)
 	EmitErr(string, string) interface{ Close() }
 	Listen() error
 }
@@ -49,9 +57,12 @@ func EmitWs[T any](s Socket, ev string, obj T) {
 	s.Emit(ev, string(o))
 }
 
+// socketMsg is what goes over the connection. Seq is set on the events of
+// a verifier's event log so that clients can ask for the ones they missed.
 type socketMsg struct {
 	EventName string `json:"eventName"`
 	Data string `json:"data"`
+	Seq int64 `json:"seq,omitempty"`
 }
 
 func NewWebSocket(w http.ResponseWriter, r *http.Request) (Socket, error) {
@@ -84,11 +95,25 @@ func (s *wsocket) Close() {
 }
 
 func (s *wsocket) Emit(evName string, data string) {
-	res, err := json.Marshal(socketMsg{EventName: evName, Data: data})
+	s.EmitSeq(evName, data, 0)
+}
+
+func (s *wsocket) EmitSeq(evName string, data string, seq int64) {
+	res, err := json.Marshal(socketMsg{EventName: evName, Data: data, Seq: seq})
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	s.conn.WriteMessage(websocket.TextMessage, res)
+	// gorilla allows only one concurrent writer per connection.
+	s.writeMu.Lock()
+	s.conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+	err = s.conn.WriteMessage(websocket.TextMessage, res)
+	s.writeMu.Unlock()
+
+	// closing the connection ends Listen, which lets the owner of the
+	// socket clean up after it.
+	if err != nil {
+		s.conn.Close()
+	}
 }
 
 func (s *wsocket) EmitErr(evName string, errMsg string) interface{ Close() } {
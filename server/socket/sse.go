@@ -0,0 +1,124 @@
+package socket
+
+import (
+	"context"
+	"email_verify/respond"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSE_HEARTBEAT_INTERVAL is how often a comment is sent on an idle stream
+// so that proxies don't close it.
+const SSE_HEARTBEAT_INTERVAL = 15 * time.Second
+
+// sseSocket sends events as a Server-Sent Events stream. It only goes one
+// way, so the handlers of On and Once are never called.
+type sseSocket struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	ctx     context.Context
+	cancel  context.CancelFunc
+	writeMu sync.Mutex
+}
+
+// NewSSESocket starts an event stream on w. Events are written with their
+// name as the SSE event, their data as JSON and, for the events of a
+// verifier's event log, their number as the SSE id.
+func NewSSESocket(w http.ResponseWriter, r *http.Request) (Socket, error) {
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// stops nginx and the like from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return nil, errors.New("streaming is not supported.")
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	return &sseSocket{w: w, rc: rc, ctx: ctx, cancel: cancel}, nil
+}
+
+func (s *sseSocket) On(evName string, f func([]byte)) {}
+
+func (s *sseSocket) Once(evName string, f func([]byte)) {}
+
+// Close ends the stream. Nothing is written to it afterwards, so it can
+// be called before the handler that owns w returns.
+func (s *sseSocket) Close() {
+	s.writeMu.Lock()
+	s.cancel()
+	s.writeMu.Unlock()
+}
+
+func (s *sseSocket) Emit(evName string, data string) {
+	s.EmitSeq(evName, data, 0)
+}
+
+func (s *sseSocket) EmitSeq(evName string, data string, seq int64) {
+	// events carry plain strings too, like batch numbers, which are
+	// quoted to keep every payload JSON.
+	if !json.Valid([]byte(data)) {
+		d, _ := json.Marshal(data)
+		data = string(d)
+	}
+
+	msg := strings.Builder{}
+	if seq != 0 {
+		fmt.Fprintf(&msg, "id: %d\n", seq)
+	}
+	fmt.Fprintf(&msg, "event: %s\ndata: %s\n\n", evName, data)
+
+	s.write(msg.String())
+}
+
+func (s *sseSocket) EmitErr(evName string, errMsg string) interface{ Close() } {
+	EmitWs(s, evName, respond.ResponseStruct{Err: true, Msg: errMsg})
+	return s
+}
+
+// Listen keeps the stream open, sending heartbeats, until the client goes
+// away or the socket is closed.
+func (s *sseSocket) Listen() error {
+	t := time.NewTicker(SSE_HEARTBEAT_INTERVAL)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-t.C:
+			s.write(": heartbeat\n\n")
+		}
+	}
+}
+
+func (s *sseSocket) write(msg string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	s.rc.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+
+	_, err := s.w.Write([]byte(msg))
+	if err == nil {
+		err = s.rc.Flush()
+	}
+
+	// like a websocket, a client that can't keep up is disconnected.
+	if err != nil {
+		s.cancel()
+	}
+}
@@ -0,0 +1,162 @@
+package verifier
+
+import (
+	"email_verify/schema"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+
+	emailverifier "github.com/AfterShip/email-verifier"
+)
+
+const (
+	AFTERSHIP_CHECKER = "aftership"
+	TEST_CHECKER = "test"
+	SIMULATED_CHECKER = "simulated"
+)
+
+// Checker is a verification backend that checks a single email.
+type Checker interface {
+	Verify(email string) (*emailverifier.Result, error)
+}
+
+// MxResolver is implemented by checkers that look the MX hosts of
+// domains up themselves, so that the host limiter throttles the hosts
+// they connect to without a lookup of its own.
+type MxResolver interface {
+	MxHosts(domain string) ([]string, error)
+}
+
+// CheckerOptions holds the settings a backend is built with for a
+// single check.
+type CheckerOptions struct {
+	Proxy string
+	// look the address up on gravatar.com.
+	Gravatar bool
+	// what to introduce the checks with, SMTP_HELLO_NAME and
+	// SMTP_FROM_EMAIL for the empty fields.
+	Identity schema.SMTPIdentity
+	// looks up MX records, net.DefaultResolver when nil.
+	Resolver *net.Resolver
+	// ProxyDialed is called with the outcome of every connection made
+	// through Proxy, nil when it was made.
+	ProxyDialed func(err error)
+}
+
+type CheckerFactory func(opts CheckerOptions) Checker
+
+type checkerRegistry struct {
+	factories map[string]CheckerFactory
+	defaultName string
+	sync.RWMutex
+}
+
+var checkers = checkerRegistry{
+	factories: make(map[string]CheckerFactory),
+	defaultName: AFTERSHIP_CHECKER,
+}
+
+func RegisterChecker(name string, f CheckerFactory) {
+	checkers.Lock()
+	checkers.factories[name] = f
+	checkers.Unlock()
+}
+
+func CheckerNames() []string {
+	checkers.RLock()
+	defer checkers.RUnlock()
+
+	names := []string{}
+	for name := range checkers.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func HasChecker(name string) bool {
+	checkers.RLock()
+	defer checkers.RUnlock()
+
+	_, ok := checkers.factories[name]
+	return ok
+}
+
+// SetDefaultChecker sets the backend used when a run or request doesn't
+// name one.
+func SetDefaultChecker(name string) error {
+	if !HasChecker(name) {
+		return errors.New("unknown checker: " + name)
+	}
+
+	checkers.Lock()
+	checkers.defaultName = name
+	checkers.Unlock()
+
+	return nil
+}
+
+func DefaultChecker() string {
+	checkers.RLock()
+	defer checkers.RUnlock()
+	return checkers.defaultName
+}
+
+// NewChecker builds the named backend, falling back to the default one
+// when name is empty.
+func NewChecker(name string, opts CheckerOptions) (Checker, error) {
+	if name == "" {
+		name = DefaultChecker()
+	}
+
+	checkers.RLock()
+	f, ok := checkers.factories[name]
+	checkers.RUnlock()
+
+	if !ok {
+		return nil, errors.New("unknown checker: " + name)
+	}
+
+	return f(opts), nil
+}
+
+func init() {
+	RegisterChecker(AFTERSHIP_CHECKER, func(opts CheckerOptions) Checker {
+		return newAftershipChecker(opts)
+	})
+
+	RegisterChecker(TEST_CHECKER, func(opts CheckerOptions) Checker {
+		return NewTestVerifier().
+			EnableSMTPCheck().
+			EnableCatchAllCheck().
+			Proxy(opts.Proxy)
+	})
+}
+
+// RegisterSimulatedChecker registers the AfterShip checker under
+// SIMULATED_CHECKER, looking MX records up with resolver, the DNS server
+// of a simenv environment. It connects through the proxy of the run, so
+// that proxy rotation and failover are exercised too, or through
+// proxyURI when the run has none. The proxies of the run have to lead to
+// the environment, like its proxyURI and Env.ProxyURIs. Its domains are cached apart from DomainCache so that
+// they don't leak into the checks of the other checkers.
+func RegisterSimulatedChecker(proxyURI string, resolver *net.Resolver) {
+	cache := &domainCache{
+		ttl: DEFAULT_DOMAIN_CACHE_TTL,
+		entries: make(map[string]DomainInfo),
+		loads: make(map[string]*domainLoad),
+	}
+
+	RegisterChecker(SIMULATED_CHECKER, func(opts CheckerOptions) Checker {
+		if opts.Proxy == "" {
+			opts.Proxy = proxyURI
+		}
+		opts.Resolver = resolver
+
+		c := newAftershipChecker(opts)
+		c.cache = cache
+		return c
+	})
+}
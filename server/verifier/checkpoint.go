@@ -0,0 +1,61 @@
+package verifier
+
+import (
+	"database/sql"
+	"email_verify/db"
+	"encoding/json"
+	"fmt"
+)
+
+// checkpoint saves the run so that it can be picked up again from the
+// last completed batch if the server goes down.
+func (v *Verifier) checkpoint() {
+	d := v.Snapshot()
+	data, err := json.Marshal(d)
+	if err != nil {
+		fmt.Println("checkpoint:", err.Error())
+		return
+	}
+
+	if err := db.SaveRun(v.db, v.File.Id, d.State, data); err != nil {
+		fmt.Println("checkpoint:", err.Error())
+	}
+}
+
+// RestoreInterruptedRuns adds the runs that were still going or queued
+// when the server stopped to the VerifierManager in the INTERRUPTED
+// state. They continue from the next batch once run-verifier is sent,
+// or are queued right away when autoResume is set.
+func RestoreInterruptedRuns(dbConn *sql.DB, autoResume bool) error {
+	runs, err := db.GetRunsInState(dbConn, RUNNING, PAUSED, QUEUED, INTERRUPTED)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range runs {
+		v := &Verifier{db: dbConn}
+		v.File.Id = r.FileId
+
+		if err := json.Unmarshal(r.Data, &v.VerifierData); err != nil {
+			fmt.Println("restore run", r.FileId, ":", err.Error())
+			continue
+		}
+		v.setDefaults()
+
+		v.State = INTERRUPTED
+		v.interrupted = true
+		v.checkpoint()
+
+		VerifierManager.Add(r.FileId, v)
+
+		fmt.Println("restored interrupted run for file", r.FileId)
+
+		if autoResume {
+			if err := VerifierManager.Enqueue(r.FileId, v.Priority); err != nil {
+				fmt.Println("resume run", r.FileId, ":", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errNotStarted is returned by startControl for a run cancelled before
+// it was started.
+var errNotStarted = errors.New("verifier was cancelled before it started.")
+
+// startControl sets the run going. The returned channel is to be closed
+// once it has stopped.
+func (v *Verifier) startControl() (chan struct{}, error) {
+	v.ctrl.Lock()
+	defer v.ctrl.Unlock()
+
+	switch v.State {
+	case CANCELLED:
+		return nil, errNotStarted
+	case RUNNING, PAUSED:
+		return nil, errors.New("verifier is already running.")
+	}
+
+	v.ctrl.ctx, v.ctrl.cancel = context.WithCancel(context.Background())
+	v.ctrl.resume = nil
+	v.ctrl.done = make(chan struct{})
+	v.State = RUNNING
+
+	return v.ctrl.done, nil
+}
+
+// Wait blocks until the run, if one was started, has stopped and saved
+// its last checkpoint.
+func (v *Verifier) Wait() {
+	v.ctrl.Lock()
+	done := v.ctrl.done
+	v.ctrl.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+func (v *Verifier) setState(state string) {
+	v.ctrl.Lock()
+	v.State = state
+	v.ctrl.Unlock()
+}
+
+func (v *Verifier) Pause() error {
+	v.ctrl.Lock()
+	defer v.ctrl.Unlock()
+
+	if v.State != RUNNING {
+		return errors.New("verifier is not running.")
+	}
+
+	v.ctrl.resume = make(chan struct{})
+	v.State = PAUSED
+
+	return nil
+}
+
+func (v *Verifier) Resume() error {
+	v.ctrl.Lock()
+	defer v.ctrl.Unlock()
+
+	if v.State != PAUSED {
+		return errors.New("verifier is not paused.")
+	}
+
+	// a cancelled run stays PAUSED until Run stops, but Cancel already
+	// closed its resume channel.
+	if v.ctrl.resume != nil {
+		close(v.ctrl.resume)
+		v.ctrl.resume = nil
+	}
+	v.State = RUNNING
+
+	return nil
+}
+
+func (v *Verifier) Cancel() error {
+	v.ctrl.Lock()
+
+	switch v.State {
+	case CREATED:
+		v.State = CANCELLED
+		v.ctrl.Unlock()
+		return nil
+	case INTERRUPTED, QUEUED:
+		v.State = CANCELLED
+		// checkpoint reads the state, so ctrl can't be held meanwhile.
+		v.ctrl.Unlock()
+		v.checkpoint()
+		return nil
+	case RUNNING, PAUSED:
+	default:
+		v.ctrl.Unlock()
+		return errors.New("verifier is not running.")
+	}
+
+	defer v.ctrl.Unlock()
+
+	v.ctrl.cancel()
+
+	if v.ctrl.resume != nil {
+		close(v.ctrl.resume)
+		v.ctrl.resume = nil
+	}
+
+	return nil
+}
+
+func (v *Verifier) isCancelled() bool {
+	v.ctrl.Lock()
+	defer v.ctrl.Unlock()
+	return v.ctrl.ctx != nil && v.ctrl.ctx.Err() != nil
+}
+
+// waitIfPaused blocks while the verifier is paused and returns false
+// once the run has been cancelled.
+func (v *Verifier) waitIfPaused() bool {
+	v.ctrl.Lock()
+	ctx := v.ctrl.ctx
+	resume := v.ctrl.resume
+	v.ctrl.Unlock()
+
+	if resume != nil {
+		select {
+		case <-resume:
+		case <-ctx.Done():
+		}
+	}
+
+	return ctx.Err() == nil
+}
+
+// sleep waits for ms milliseconds (plus any time spent paused) and
+// returns false if the run was cancelled meanwhile.
+func (v *Verifier) sleep(ms int) bool {
+	v.ctrl.Lock()
+	ctx := v.ctrl.ctx
+	v.ctrl.Unlock()
+
+	t := time.NewTimer(time.Duration(ms) * time.Millisecond)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+		return false
+	}
+
+	return v.waitIfPaused()
+}
@@ -0,0 +1,356 @@
+package verifier
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	emailverifier "github.com/AfterShip/email-verifier"
+)
+
+// fakeDriver answers the queries of a run without a database. The emails
+// of a file are returned for sp_get_emails_for_verification and every
+// other statement succeeds without rows.
+type fakeDriver struct {
+	emails []string
+	execs []string
+	mu sync.Mutex
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+type fakeStmt struct {
+	d *fakeDriver
+	query string
+}
+
+type fakeRows struct {
+	values []string
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	return nil
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	s.d.execs = append(s.d.execs, s.query)
+	s.d.mu.Unlock()
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "sp_get_emails_for_verification") {
+		return &fakeRows{values: s.d.emails}, nil
+	}
+	return &fakeRows{}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"email_id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.values[0]
+	r.values = r.values[1:]
+	return nil
+}
+
+const RACE_CHECKER = "race-test"
+
+// raceChecker finds every email deliverable after a short wait so that
+// the run is still going while the tests poke at it.
+type raceChecker struct{}
+
+func (raceChecker) Verify(email string) (*emailverifier.Result, error) {
+	time.Sleep(time.Millisecond)
+
+	return &emailverifier.Result{
+		Email: email,
+		Reachable: "yes",
+		Syntax: emailverifier.Syntax{Valid: true},
+		HasMxRecords: true,
+		SMTP: &emailverifier.SMTP{HostExists: true, Deliverable: true},
+	}, nil
+}
+
+const GREYLIST_CHECKER = "greylist-test"
+
+// greylistChecker gets a greylisting reply for every email.
+type greylistChecker struct{}
+
+func (greylistChecker) Verify(email string) (*emailverifier.Result, error) {
+	return &emailverifier.Result{
+		Email: email,
+		Syntax: emailverifier.Syntax{Valid: true},
+		HasMxRecords: true,
+	}, errors.New("450 4.7.1 greylisted, please retry later")
+}
+
+var (
+	registerFakes sync.Once
+	fakeDrivers int
+)
+
+func newTestVerifier(t *testing.T, count int) *Verifier {
+	v, _ := newTestVerifierDriver(t, count)
+	return v
+}
+
+func newTestVerifierDriver(t *testing.T, count int) (*Verifier, *fakeDriver) {
+	registerFakes.Do(func() {
+		RegisterChecker(RACE_CHECKER, func(opts CheckerOptions) Checker {
+			return raceChecker{}
+		})
+		RegisterChecker(GREYLIST_CHECKER, func(opts CheckerOptions) Checker {
+			return greylistChecker{}
+		})
+	})
+
+	d := &fakeDriver{}
+	for i := 0; i < count; i++ {
+		d.emails = append(d.emails, fmt.Sprintf("user%d@example%d.com", i, i % 20))
+	}
+
+	fakeDrivers++
+	name := fmt.Sprintf("fake-%d", fakeDrivers)
+	sql.Register(name, d)
+
+	dbConn, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+
+	v, err := NewVerifier(int64(fakeDrivers), VerifierOptions{
+		BatchSize: 50,
+		DelayMs: 1,
+		Concurrency: 4,
+		Checker: RACE_CHECKER,
+	}, dbConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v, d
+}
+
+func startRun(v *Verifier) chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- v.Run()
+	}()
+	return done
+}
+
+func waitRun(t *testing.T, done chan error) {
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("run didn't stop")
+	}
+}
+
+// poke calls the methods the web routes call on a running verifier
+// until stop is closed.
+func poke(v *Verifier, stop chan struct{}) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+
+	loop := func(f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				f(i)
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+
+	loop(func(i int) { v.Snapshot() })
+	loop(func(i int) { v.SetConcurrency(i % 8 + 1) })
+	loop(func(i int) {
+		if v.Pause() == nil {
+			time.Sleep(time.Millisecond)
+			v.Resume()
+		}
+	})
+
+	return wg
+}
+
+func TestRunWithConcurrentControl(t *testing.T) {
+	v := newTestVerifier(t, 300)
+
+	stop := make(chan struct{})
+	wg := poke(v, stop)
+
+	waitRun(t, startRun(v))
+	close(stop)
+	wg.Wait()
+
+	if s := v.GetState(); s != DONE {
+		t.Fatalf("state is %s, want %s", s, DONE)
+	}
+
+	d := v.Snapshot()
+	progress := 0
+	for _, l := range d.CompletedBatches {
+		progress += l[0].Progress
+	}
+	if progress != 300 {
+		t.Fatalf("%d emails verified, want 300", progress)
+	}
+}
+
+func TestCancelWhileRunning(t *testing.T) {
+	v := newTestVerifier(t, 1000)
+
+	stop := make(chan struct{})
+	wg := poke(v, stop)
+
+	done := startRun(v)
+	time.Sleep(50 * time.Millisecond)
+
+	if err := v.Cancel(); err != nil && v.GetState() != DONE {
+		t.Fatal(err)
+	}
+
+	waitRun(t, done)
+	close(stop)
+	wg.Wait()
+
+	if s := v.GetState(); s != CANCELLED && s != DONE {
+		t.Fatalf("state is %s, want %s", s, CANCELLED)
+	}
+}
+
+func TestCancelQueued(t *testing.T) {
+	for _, state := range []string{CREATED, QUEUED, INTERRUPTED} {
+		v := newTestVerifier(t, 1)
+		v.State = state
+
+		cancelled := make(chan error, 1)
+		go func() {
+			cancelled <- v.Cancel()
+		}()
+
+		select {
+		case err := <-cancelled:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("cancel of a %s run didn't return", state)
+		}
+
+		if s := v.GetState(); s != CANCELLED {
+			t.Fatalf("state is %s, want %s", s, CANCELLED)
+		}
+
+		// a cancelled run doesn't start.
+		waitRun(t, startRun(v))
+	}
+}
+
+func TestRemoveWaitsForRun(t *testing.T) {
+	v := newTestVerifier(t, 1000)
+	VerifierManager.Add(v.File.Id, v)
+
+	done := startRun(v)
+	time.Sleep(50 * time.Millisecond)
+
+	if !VerifierManager.Remove(v.File.Id) {
+		t.Fatal("verifier wasn't removed")
+	}
+
+	// Run may still be returning, but it has stopped for good.
+	if s := v.GetState(); s != CANCELLED && s != DONE {
+		t.Fatalf("state is %s once removed, want it stopped", s)
+	}
+
+	if VerifierManager.Get(v.File.Id) != nil {
+		t.Fatal("verifier is still in the manager")
+	}
+
+	waitRun(t, done)
+}
+
+// TestGreylistedFlushedBeforeRecheck checks that a greylisted result is in
+// the db before its recheck is queued, so the recheck can't be overwritten
+// by a later flush.
+func TestGreylistedFlushedBeforeRecheck(t *testing.T) {
+	v, d := newTestVerifierDriver(t, 1)
+	v.Checker = GREYLIST_CHECKER
+
+	waitRun(t, startRun(v))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	flushed := false
+	for _, q := range d.execs {
+		if strings.Contains(q, "CREATE TEMPORARY TABLE") {
+			flushed = true
+		}
+		if strings.Contains(q, "insert into email_rechecks") {
+			if !flushed {
+				t.Fatal("recheck queued before the greylisted result was written")
+			}
+			return
+		}
+	}
+
+	t.Fatal("the greylisted email wasn't queued for a recheck")
+}
@@ -0,0 +1,161 @@
+package verifier
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_DOMAIN_CACHE_TTL = time.Hour
+	// domains that don't exist or have no mail server are kept for less
+	// time, in case they are being set up.
+	DOMAIN_FAILURE_TTL = 5 * time.Minute
+)
+
+// DomainInfo is what is known about a domain independent of the mailbox
+// being checked.
+type DomainInfo struct {
+	Domain string `json:"domain"`
+	MxHosts []string `json:"mxHosts"`
+	HasMxRecords bool `json:"hasMxRecords"`
+	IsDisposable bool `json:"isDisposable"`
+	IsHostExists bool `json:"isHostExists"`
+	IsCatchAll bool `json:"isCatchAll"`
+	CatchAllChecked bool `json:"catchAllChecked"`
+	// LookupError is why the domain has no mail host, when it has none.
+	LookupError string `json:"lookupError"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	lookupErr error
+}
+
+// domainLoad is a lookup of a domain in progress.
+type domainLoad struct {
+	done chan struct{}
+	info DomainInfo
+	err error
+}
+
+type domainCache struct {
+	ttl time.Duration
+	entries map[string]DomainInfo
+	loads map[string]*domainLoad
+	sync.RWMutex
+}
+
+func (c *domainCache) SetTTL(ttl time.Duration) {
+	c.Lock()
+	c.ttl = ttl
+	c.Unlock()
+}
+
+func (c *domainCache) Get(domain string) (DomainInfo, bool) {
+	domain = strings.ToLower(domain)
+
+	c.RLock()
+	d, ok := c.entries[domain]
+	c.RUnlock()
+
+	if !ok || time.Now().After(d.ExpiresAt) {
+		return DomainInfo{}, false
+	}
+
+	return d, true
+}
+
+func (c *domainCache) Set(d DomainInfo) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	ttl := c.ttl
+	if !d.IsHostExists && !d.IsDisposable && ttl > DOMAIN_FAILURE_TTL {
+		ttl = DOMAIN_FAILURE_TTL
+	}
+
+	d.Domain = strings.ToLower(d.Domain)
+	d.ExpiresAt = time.Now().Add(ttl)
+	c.entries[d.Domain] = d
+}
+
+// load returns the cached info of domain, or looks it up with lookup and
+// caches it when lookup succeeds. Checks of the same domain running at the
+// same time share a single lookup.
+func (c *domainCache) load(domain string, lookup func(domain string) (DomainInfo, error)) (DomainInfo, error) {
+	domain = strings.ToLower(domain)
+
+	if d, ok := c.Get(domain); ok {
+		return d, nil
+	}
+
+	c.Lock()
+	if l, ok := c.loads[domain]; ok {
+		c.Unlock()
+		<-l.done
+		return l.info, l.err
+	}
+
+	l := &domainLoad{done: make(chan struct{})}
+	if c.loads == nil {
+		c.loads = make(map[string]*domainLoad)
+	}
+	c.loads[domain] = l
+	c.Unlock()
+
+	l.info, l.err = lookup(domain)
+	if l.err == nil {
+		c.Set(l.info)
+	}
+
+	c.Lock()
+	delete(c.loads, domain)
+	c.Unlock()
+	close(l.done)
+
+	return l.info, l.err
+}
+
+// List returns the entries that haven't expired, sorted by domain.
+func (c *domainCache) List() []DomainInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	now := time.Now()
+	list := []DomainInfo{}
+
+	for _, d := range c.entries {
+		if now.Before(d.ExpiresAt) {
+			list = append(list, d)
+		}
+	}
+
+	slices.SortFunc(list, func(a, b DomainInfo) int {
+		return strings.Compare(a.Domain, b.Domain)
+	})
+
+	return list
+}
+
+// Flush removes domain from the cache, or every entry if domain is empty.
+func (c *domainCache) Flush(domain string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if domain == "" {
+		c.entries = make(map[string]DomainInfo)
+		return
+	}
+
+	delete(c.entries, strings.ToLower(domain))
+}
+
+// DomainCache is shared by every run and by the quick verify route.
+var DomainCache = domainCache{
+	ttl: DEFAULT_DOMAIN_CACHE_TTL,
+	entries: make(map[string]DomainInfo),
+	loads: make(map[string]*domainLoad),
+}
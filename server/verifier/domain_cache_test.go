@@ -0,0 +1,89 @@
+package verifier
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestDomainCache() *domainCache {
+	return &domainCache{
+		ttl: DEFAULT_DOMAIN_CACHE_TTL,
+		entries: make(map[string]DomainInfo),
+		loads: make(map[string]*domainLoad),
+	}
+}
+
+// TestDomainCacheLoadOnce checks that checks of the same domain running
+// at the same time share a single lookup.
+func TestDomainCacheLoadOnce(t *testing.T) {
+	c := newTestDomainCache()
+
+	var lookups atomic.Int32
+	lookup := func(domain string) (DomainInfo, error) {
+		lookups.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return DomainInfo{Domain: domain, IsHostExists: true, MxHosts: []string{"mx." + domain}}, nil
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := c.load("Example.com", lookup)
+			if err != nil || len(info.MxHosts) != 1 {
+				t.Errorf("load returned %v, %v", info, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := lookups.Load(); n != 1 {
+		t.Fatalf("domain looked up %d times, want 1", n)
+	}
+}
+
+func TestDomainCacheLoadTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		info DomainInfo
+		err error
+		cached bool
+		ttl time.Duration
+	}{
+		{name: "mail host", info: DomainInfo{IsHostExists: true}, cached: true, ttl: DEFAULT_DOMAIN_CACHE_TTL},
+		{name: "disposable", info: DomainInfo{IsDisposable: true}, cached: true, ttl: DEFAULT_DOMAIN_CACHE_TTL},
+		{name: "no mail host", info: DomainInfo{lookupErr: errors.New("no such host")}, cached: true, ttl: DOMAIN_FAILURE_TTL},
+		{name: "lookup failed", err: errors.New("i/o timeout")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestDomainCache()
+
+			_, err := c.load("example.com", func(domain string) (DomainInfo, error) {
+				tt.info.Domain = domain
+				return tt.info, tt.err
+			})
+			if err != tt.err {
+				t.Fatalf("load returned %v, want %v", err, tt.err)
+			}
+
+			d, ok := c.Get("example.com")
+			if ok != tt.cached {
+				t.Fatalf("cached is %v, want %v", ok, tt.cached)
+			}
+
+			if !ok {
+				return
+			}
+
+			if ttl := time.Until(d.ExpiresAt); ttl > tt.ttl || ttl < tt.ttl - time.Minute {
+				t.Fatalf("entry expires in %v, want %v", ttl, tt.ttl)
+			}
+		})
+	}
+}
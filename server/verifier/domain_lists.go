@@ -0,0 +1,64 @@
+package verifier
+
+import (
+	"database/sql"
+	"email_verify/schema"
+	"strings"
+
+	emailverifier "github.com/AfterShip/email-verifier"
+)
+
+var addressParser = emailverifier.NewVerifier()
+
+// domainLists maps the domains a user listed to their list.
+type domainLists map[string]string
+
+// match returns the list the domain of email, or one of its parent
+// domains, is on. Emails with a bad syntax match no list.
+func (l domainLists) match(email string) string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	syntax := addressParser.ParseAddress(email)
+	if !syntax.Valid {
+		return ""
+	}
+
+	d := strings.ToLower(syntax.Domain)
+
+	for {
+		if list, ok := l[d]; ok {
+			return list
+		}
+
+		i := strings.Index(d, ".")
+		if i == -1 || !strings.Contains(d[i+1:], ".") {
+			return ""
+		}
+		d = d[i+1:]
+	}
+}
+
+// applyDomainList settles e by the list its domain is on, in place of the
+// checks of a checker. error_msg stays empty even for a blocked domain, as
+// an email with an error is verified again; matched_list tells why.
+func applyDomainList(e *schema.EmailDetails, list string) {
+	e.MatchedList = list
+	e.IsValidSyntax = true
+	e.ErrorCode = string(ERR_NONE)
+	e.ErrorMsg = sql.NullString{String: "", Valid: true}
+
+	switch list {
+	case schema.LIST_TRUSTED:
+		e.Reachable = "yes"
+		e.IsDeliverable = true
+		e.IsHostExists = true
+		e.HasMxRecords = true
+	case schema.LIST_DISPOSABLE:
+		e.Reachable = "unknown"
+		e.IsDisposable = true
+	case schema.LIST_BLOCKED:
+		e.Reachable = "no"
+	}
+}
@@ -0,0 +1,161 @@
+package verifier
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	emailverifier "github.com/AfterShip/email-verifier"
+)
+
+type ErrorCode string
+
+const (
+	ERR_NONE ErrorCode = ""
+	ERR_DNS ErrorCode = "dns"
+	ERR_CONNECT_TIMEOUT ErrorCode = "connect_timeout"
+	ERR_SMTP_4XX ErrorCode = "smtp_4xx"
+	ERR_GREYLISTED ErrorCode = "greylisted"
+	ERR_SMTP_5XX ErrorCode = "smtp_5xx"
+	ERR_BLOCKED ErrorCode = "blocked"
+	ERR_PROXY ErrorCode = "proxy"
+	ERR_UNKNOWN ErrorCode = "unknown"
+)
+
+var ErrorCodes = []ErrorCode{
+	ERR_DNS,
+	ERR_CONNECT_TIMEOUT,
+	ERR_SMTP_4XX,
+	ERR_GREYLISTED,
+	ERR_SMTP_5XX,
+	ERR_BLOCKED,
+	ERR_PROXY,
+	ERR_UNKNOWN,
+}
+
+// IsRetryable reports whether an email that failed with this code may
+// succeed if it is checked again later.
+func (c ErrorCode) IsRetryable() bool {
+	switch c {
+	case ERR_CONNECT_TIMEOUT, ERR_SMTP_4XX, ERR_PROXY:
+		return true
+	}
+	return false
+}
+
+func containsAny(s string, subs ...string) bool {
+	s = strings.ToLower(s)
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// smtpStatus returns the reply code at the start of an SMTP error, or 0.
+func smtpStatus(s string) int {
+	if len(s) < 3 {
+		return 0
+	}
+
+	status, err := strconv.Atoi(s[:3])
+	if err != nil || status < 200 || status > 599 {
+		return 0
+	}
+
+	return status
+}
+
+// isGreylisting reports whether a 4xx reply asks to come back later
+// rather than reporting a problem.
+func isGreylisting(status int, msg string) bool {
+	if status != 450 && status != 451 {
+		return false
+	}
+	return containsAny(msg, "greylist", "graylist", "grey-list", "gray-list", "try again later", "please retry", "retry later", "temporarily deferred")
+}
+
+func classifyStatus(status int, msg string) ErrorCode {
+	if isGreylisting(status, msg) {
+		return ERR_GREYLISTED
+	}
+
+	if status >= 500 {
+		if containsAny(msg, "spamhaus", "proofpoint", "cloudmark", "banned", "blacklist", "blocked", "block list", "denied") {
+			return ERR_BLOCKED
+		}
+		return ERR_SMTP_5XX
+	}
+	return ERR_SMTP_4XX
+}
+
+// ClassifyError sorts an error returned by a Checker into an ErrorCode.
+func ClassifyError(err error) ErrorCode {
+	if err == nil {
+		return ERR_NONE
+	}
+
+	var lookupErr *emailverifier.LookupError
+	if errors.As(err, &lookupErr) && lookupErr == nil {
+		return ERR_UNKNOWN
+	}
+
+	msg := err.Error()
+	details := msg
+
+	if lookupErr != nil {
+		details = lookupErr.Details
+	}
+
+	// a reply of the mail server is about the email, whatever its text
+	// says, like "listed as open proxy".
+	if status := smtpStatus(details); status >= 400 {
+		return classifyStatus(status, details)
+	}
+
+	// only the proxy failing to connect is a proxy error. Failures of the
+	// mail server reached through it are sorted like any other.
+	var proxyErr *ProxyError
+	if errors.As(err, &proxyErr) {
+		return ERR_PROXY
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return ERR_CONNECT_TIMEOUT
+		}
+		return ERR_DNS
+	}
+
+	if lookupErr != nil {
+		switch lookupErr.Message {
+		case emailverifier.ErrBlocked:
+			return ERR_BLOCKED
+		case emailverifier.ErrTimeout:
+			return ERR_CONNECT_TIMEOUT
+		case emailverifier.ErrNoSuchHost:
+			return ERR_DNS
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ERR_CONNECT_TIMEOUT
+	}
+
+	switch {
+	case containsAny(details, "no such host", "no mx records"):
+		return ERR_DNS
+	case containsAny(details, "timed out", "timeout"):
+		return ERR_CONNECT_TIMEOUT
+	case containsAny(details, "temporarily unavailable", "try again later"):
+		return ERR_SMTP_4XX
+	case containsAny(details, "blocked", "blacklist", "banned", "spamhaus", "denied"):
+		return ERR_BLOCKED
+	}
+
+	return ERR_UNKNOWN
+}
@@ -0,0 +1,74 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	emailverifier "github.com/AfterShip/email-verifier"
+)
+
+func TestClassifyError(t *testing.T) {
+	var nilLookup *emailverifier.LookupError
+
+	tests := []struct {
+		name string
+		err error
+		code ErrorCode
+	}{
+		{name: "nil", err: nil, code: ERR_NONE},
+		{name: "nil lookup error", err: nilLookup, code: ERR_UNKNOWN},
+		{name: "greylisted", err: errors.New("450 4.2.0 greylisted, please retry later"), code: ERR_GREYLISTED},
+		{name: "451 try again later", err: errors.New("451 4.7.1 try again later"), code: ERR_GREYLISTED},
+		{name: "4xx", err: errors.New("421 too many connections"), code: ERR_SMTP_4XX},
+		{name: "5xx", err: errors.New("550 5.1.1 user unknown"), code: ERR_SMTP_5XX},
+		{name: "5xx blocked", err: errors.New("554 5.7.1 listed by spamhaus"), code: ERR_BLOCKED},
+		{name: "reply about a proxy", err: errors.New("554 5.7.1 listed as open proxy"), code: ERR_SMTP_5XX},
+		{name: "proxy", err: &ProxyError{Err: errors.New("connection refused")}, code: ERR_PROXY},
+		{name: "wrapped proxy", err: fmt.Errorf("dial: %w", &ProxyError{Err: errors.New("EOF")}), code: ERR_PROXY},
+		{name: "dns", err: &net.DNSError{Err: "no such host", Name: "nowhere.test", IsNotFound: true}, code: ERR_DNS},
+		{name: "dns timeout", err: &net.DNSError{Err: "i/o timeout", Name: "slow.test", IsTimeout: true}, code: ERR_CONNECT_TIMEOUT},
+		{name: "lookup blocked", err: &emailverifier.LookupError{Message: emailverifier.ErrBlocked, Details: "connection reset"}, code: ERR_BLOCKED},
+		{name: "lookup timeout", err: &emailverifier.LookupError{Message: emailverifier.ErrTimeout, Details: "dial tcp"}, code: ERR_CONNECT_TIMEOUT},
+		{name: "lookup no such host", err: &emailverifier.LookupError{Message: emailverifier.ErrNoSuchHost, Details: "dial tcp"}, code: ERR_DNS},
+		{name: "lookup reply", err: &emailverifier.LookupError{Message: emailverifier.ErrServerUnavailable, Details: "550 5.1.1 no such user"}, code: ERR_SMTP_5XX},
+		{name: "deadline", err: context.DeadlineExceeded, code: ERR_CONNECT_TIMEOUT},
+		{name: "no mx records", err: errors.New("No MX records found"), code: ERR_DNS},
+		{name: "timed out text", err: errors.New("read: connection timed out"), code: ERR_CONNECT_TIMEOUT},
+		{name: "unavailable text", err: errors.New("service temporarily unavailable"), code: ERR_SMTP_4XX},
+		{name: "banned text", err: errors.New("client host banned"), code: ERR_BLOCKED},
+		{name: "other", err: errors.New("EOF"), code: ERR_UNKNOWN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := ClassifyError(tt.err); code != tt.code {
+				t.Fatalf("ClassifyError(%v) is %q, want %q", tt.err, code, tt.code)
+			}
+		})
+	}
+}
+
+func TestErrorCodeIsRetryable(t *testing.T) {
+	tests := []struct {
+		code ErrorCode
+		retryable bool
+	}{
+		{ERR_CONNECT_TIMEOUT, true},
+		{ERR_SMTP_4XX, true},
+		{ERR_PROXY, true},
+		{ERR_GREYLISTED, false},
+		{ERR_DNS, false},
+		{ERR_SMTP_5XX, false},
+		{ERR_BLOCKED, false},
+		{ERR_UNKNOWN, false},
+	}
+
+	for _, tt := range tests {
+		if r := tt.code.IsRetryable(); r != tt.retryable {
+			t.Errorf("%q retryable is %v, want %v", tt.code, r, tt.retryable)
+		}
+	}
+}
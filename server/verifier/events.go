@@ -0,0 +1,190 @@
+package verifier
+
+import (
+	"email_verify/socket"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// EVENT_LOG_SIZE is how many of the latest events a run keeps.
+	EVENT_LOG_SIZE = 1000
+	// EVENT_REPLAY_LIMIT is the most events replayed to a client, after
+	// compaction. Clients that missed more get a summary instead.
+	EVENT_REPLAY_LIMIT = 200
+)
+
+// EventHub has the sockets watching each file, by file id. Every event a
+// verifier emits goes to all of the sockets of its file.
+var EventHub = socket.NewHub()
+
+type Event struct {
+	Seq int64 `json:"seq"`
+	Name string `json:"name"`
+	Data string `json:"data"`
+	At time.Time `json:"at"`
+}
+
+// eventLog numbers the events of a run and keeps the latest of them so
+// that a client that reconnects can catch up on what it missed.
+type eventLog struct {
+	events []Event
+	lastSeq int64
+	sync.Mutex
+}
+
+// EventSummary is sent in place of the missed events when there are too
+// many of them, or they are no longer in the log.
+type EventSummary struct {
+	FromSeq int64 `json:"fromSeq"`
+	LastSeq int64 `json:"lastSeq"`
+	Missed int64 `json:"missed"`
+	Verifier VerifierData `json:"verifier"`
+}
+
+// Emit numbers the event, keeps it in the event log and sends it to
+// everyone watching the file of v. The log stays locked while the event is
+// queued for the sockets so that clients get the events in the order of
+// their numbers. The sockets are written to by the hub, without it.
+func (v *Verifier) Emit(ev string, data string) {
+	l := &v.events
+
+	l.Lock()
+	defer l.Unlock()
+
+	EventHub.EmitSeq(v.File.Id, ev, data, l.add(ev, data))
+}
+
+// add numbers the event and keeps it, dropping the oldest one when the
+// log is full. The log must be locked.
+func (l *eventLog) add(ev string, data string) int64 {
+	l.lastSeq++
+	l.events = append(l.events, Event{Seq: l.lastSeq, Name: ev, Data: data, At: time.Now()})
+
+	if len(l.events) > EVENT_LOG_SIZE {
+		l.events = l.events[len(l.events) - EVENT_LOG_SIZE:]
+	}
+
+	return l.lastSeq
+}
+
+// emitWs marshals obj and sends it to everyone watching the file of v.
+func emitWs[T any](v *Verifier, ev string, obj T) {
+	o, err := json.Marshal(obj)
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	v.Emit(ev, string(o))
+}
+
+// EventsSince returns the events after lastSeq, with the progress events
+// that a later one makes stale left out. ok is false when they can't all
+// be given: some are no longer in the log, there are more than
+// EVENT_REPLAY_LIMIT, or lastSeq is from before the log was started, like
+// a run from before a server restart.
+func (v *Verifier) EventsSince(lastSeq int64) (events []Event, curSeq int64, ok bool) {
+	v.events.Lock()
+	defer v.events.Unlock()
+	return v.events.since(lastSeq)
+}
+
+func (l *eventLog) since(lastSeq int64) ([]Event, int64, bool) {
+	if lastSeq > l.lastSeq || lastSeq < 0 {
+		return nil, l.lastSeq, false
+	}
+
+	if lastSeq == l.lastSeq {
+		return []Event{}, l.lastSeq, true
+	}
+
+	if len(l.events) == 0 || l.events[0].Seq > lastSeq + 1 {
+		return nil, l.lastSeq, false
+	}
+
+	missed := l.events[lastSeq + 1 - l.events[0].Seq:]
+
+	events := []Event{}
+	for i, e := range missed {
+		if e.Name == "progress" && i + 1 < len(missed) && missed[i + 1].Name == "progress" {
+			continue
+		}
+		events = append(events, e)
+	}
+
+	if len(events) > EVENT_REPLAY_LIMIT {
+		return nil, l.lastSeq, false
+	}
+
+	return events, l.lastSeq, true
+}
+
+// SubscribeFrom sends s the events of v after lastSeq and subscribes it to
+// the events of the file, in one step so that s gets every event once and
+// in order. When the missed events can't be given an "event-summary" with
+// the current state of v is sent in their place. "replay-done" with the
+// number of the last event sent ends the replay. The returned func
+// unsubscribes s.
+func (v *Verifier) SubscribeFrom(s socket.Socket, lastSeq int64) func() {
+	var summary *EventSummary
+
+	if _, _, ok := v.EventsSince(lastSeq); !ok {
+		v.events.Lock()
+		seq := v.events.lastSeq
+		v.events.Unlock()
+
+		from := max(lastSeq, 0)
+		if lastSeq > seq {
+			from = 0
+		}
+
+		// the snapshot is taken without the log locked as emitting
+		// progress locks them the other way around. Events emitted
+		// meanwhile are sent after it.
+		summary = &EventSummary{
+			FromSeq: from,
+			LastSeq: seq,
+			Missed: seq - from,
+			Verifier: v.Snapshot(),
+		}
+		lastSeq = seq
+	}
+
+	// Emit can't queue new events while the log is locked, so none are
+	// missed or sent twice between the replay and the subscription.
+	v.events.Lock()
+	defer v.events.Unlock()
+
+	first := []socket.Message{}
+
+	if summary != nil {
+		first = append(first, message("event-summary", summary))
+	}
+
+	events, curSeq, ok := v.events.since(lastSeq)
+	if ok {
+		for _, e := range events {
+			first = append(first, socket.Message{Name: e.Name, Data: e.Data, Seq: e.Seq})
+		}
+	}
+
+	first = append(first, message("replay-done", struct {
+		LastSeq int64 `json:"lastSeq"`
+	}{curSeq}))
+
+	return EventHub.Subscribe(v.File.Id, s, first...)
+}
+
+// message marshals obj into a message of the event ev.
+func message[T any](ev string, obj T) socket.Message {
+	o, err := json.Marshal(obj)
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	return socket.Message{Name: ev, Data: string(o)}
+}
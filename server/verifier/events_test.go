@@ -0,0 +1,66 @@
+package verifier
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEventLogSince(t *testing.T) {
+	short := &eventLog{}
+	for _, name := range []string{"batch-start", "progress", "progress", "batch-done", "progress"} {
+		short.add(name, "")
+	}
+
+	// the first events are dropped once the log is full.
+	full := &eventLog{}
+	for i := 0; i < EVENT_LOG_SIZE + 500; i++ {
+		full.add("result", "")
+	}
+
+	tests := []struct {
+		name string
+		log *eventLog
+		lastSeq int64
+		seqs []int64
+		ok bool
+	}{
+		{name: "from the start", log: short, lastSeq: 0, seqs: []int64{1, 3, 4, 5}, ok: true},
+		{name: "stale progress left out", log: short, lastSeq: 1, seqs: []int64{3, 4, 5}, ok: true},
+		{name: "last progress kept", log: short, lastSeq: 4, seqs: []int64{5}, ok: true},
+		{name: "up to date", log: short, lastSeq: 5, seqs: []int64{}, ok: true},
+		{name: "ahead of the log", log: short, lastSeq: 6},
+		{name: "negative", log: short, lastSeq: -1},
+		{name: "empty log", log: &eventLog{}, lastSeq: 0, seqs: []int64{}, ok: true},
+		{name: "dropped from the log", log: full, lastSeq: 499},
+		{name: "oldest kept, too many to replay", log: full, lastSeq: 500},
+		{name: "over the replay limit", log: full, lastSeq: 1500 - EVENT_REPLAY_LIMIT - 1},
+		{name: "at the replay limit", log: full, lastSeq: 1500 - EVENT_REPLAY_LIMIT, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, curSeq, ok := tt.log.since(tt.lastSeq)
+
+			if curSeq != tt.log.lastSeq {
+				t.Fatalf("current seq is %d, want %d", curSeq, tt.log.lastSeq)
+			}
+
+			if ok != tt.ok {
+				t.Fatalf("ok is %v, want %v", ok, tt.ok)
+			}
+
+			if !ok || tt.seqs == nil {
+				return
+			}
+
+			seqs := []int64{}
+			for _, e := range events {
+				seqs = append(seqs, e.Seq)
+			}
+
+			if !slices.Equal(seqs, tt.seqs) {
+				t.Fatalf("replayed %v, want %v", seqs, tt.seqs)
+			}
+		})
+	}
+}
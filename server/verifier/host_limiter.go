@@ -0,0 +1,184 @@
+package verifier
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LIMIT_BY_DOMAIN = "domain"
+	LIMIT_BY_MX = "mx"
+)
+
+// HostLimit throttles how hard a single target is hit during a run. A
+// zero MaxConns or RatePerSec means no limit.
+type HostLimit struct {
+	By string `json:"by"`
+	MaxConns int `json:"maxConns"`
+	RatePerSec float64 `json:"ratePerSec"`
+}
+
+type hostState struct {
+	conns chan struct{}
+	next  time.Time
+	sync.Mutex
+}
+
+type mxEntry struct {
+	host string
+	once sync.Once
+}
+
+// HostLimiter hands out permits per target host. Callers waiting on one
+// host don't hold anything that callers for other hosts need.
+type HostLimiter struct {
+	limit   HostLimit
+	hosts   map[string]*hostState
+	mx      map[string]*mxEntry
+	mxHosts func(domain string) ([]string, error)
+	sync.Mutex
+}
+
+// NewHostLimiter returns a limiter for limit. mxHosts returns the MX hosts
+// of a domain, preferred first, when limit is by MX host.
+func NewHostLimiter(limit HostLimit, mxHosts func(domain string) ([]string, error)) *HostLimiter {
+	if limit.By == "" {
+		limit.By = LIMIT_BY_DOMAIN
+	}
+
+	return &HostLimiter{
+		limit: limit,
+		hosts: make(map[string]*hostState),
+		mx:    make(map[string]*mxEntry),
+		mxHosts: mxHosts,
+	}
+}
+
+func (l *HostLimiter) isUnlimited() bool {
+	return l.limit.MaxConns <= 0 && l.limit.RatePerSec <= 0
+}
+
+func emailDomain(email string) string {
+	i := strings.LastIndexByte(email, '@')
+	if i == -1 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(email[i+1:]))
+}
+
+// hostKey returns what the email is throttled by: its domain, or the
+// preferred MX host of its domain. Domains whose MX hosts can't be found
+// fall back to the domain itself.
+func (l *HostLimiter) hostKey(email string) string {
+	domain := emailDomain(email)
+
+	if l.limit.By != LIMIT_BY_MX || domain == "" {
+		return domain
+	}
+
+	l.Lock()
+	e, ok := l.mx[domain]
+	if !ok {
+		e = &mxEntry{}
+		l.mx[domain] = e
+	}
+	l.Unlock()
+
+	e.once.Do(func() {
+		e.host = domain
+		if l.mxHosts == nil {
+			return
+		}
+		if hosts, err := l.mxHosts(domain); err == nil && len(hosts) > 0 {
+			e.host = strings.ToLower(hosts[0])
+		}
+	})
+
+	return e.host
+}
+
+func (l *HostLimiter) state(key string) *hostState {
+	l.Lock()
+	defer l.Unlock()
+
+	h, ok := l.hosts[key]
+	if !ok {
+		h = &hostState{}
+		if l.limit.MaxConns > 0 {
+			h.conns = make(chan struct{}, l.limit.MaxConns)
+		}
+		l.hosts[key] = h
+	}
+
+	return h
+}
+
+// Acquire waits until the email's host has a free connection and the
+// rate limit allows another request. The returned func must be called
+// once the check is done. ok is false if ctx was cancelled while waiting.
+func (l *HostLimiter) Acquire(ctx context.Context, email string) (release func(), ok bool) {
+	if l.isUnlimited() {
+		return func() {}, true
+	}
+
+	h := l.state(l.hostKey(email))
+
+	if h.conns != nil {
+		select {
+		case h.conns <- struct{}{}:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+
+	release = func() {
+		if h.conns != nil {
+			<-h.conns
+		}
+	}
+
+	if l.limit.RatePerSec > 0 {
+		interval := time.Duration(float64(time.Second) / l.limit.RatePerSec)
+
+		h.Lock()
+		now := time.Now()
+		at := h.next
+		if at.Before(now) {
+			at = now
+		}
+		h.next = at.Add(interval)
+		h.Unlock()
+
+		if wait := time.Until(at); wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				release()
+				return nil, false
+			}
+		}
+	}
+
+	return release, true
+}
+
+// mxHosts looks the MX hosts of domain up through the run's checker, so
+// that they come from the same cache and resolver as its checks. Checkers
+// that don't look them up give none.
+func (v *Verifier) mxHosts(domain string) ([]string, error) {
+	checker, err := NewChecker(v.Checker, CheckerOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := checker.(MxResolver)
+	if !ok {
+		return nil, nil
+	}
+
+	return r.MxHosts(domain)
+}
@@ -0,0 +1,42 @@
+package verifier
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHostKey(t *testing.T) {
+	mxHosts := func(domain string) ([]string, error) {
+		switch domain {
+		case "example.com":
+			return []string{"MX1.example.net", "mx2.example.net"}, nil
+		case "nomx.test":
+			return nil, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	tests := []struct {
+		name string
+		by string
+		mxHosts func(domain string) ([]string, error)
+		email string
+		key string
+	}{
+		{name: "domain", by: LIMIT_BY_DOMAIN, mxHosts: mxHosts, email: "a@Example.com", key: "example.com"},
+		{name: "mx", by: LIMIT_BY_MX, mxHosts: mxHosts, email: "a@example.com", key: "mx1.example.net"},
+		{name: "no mx", by: LIMIT_BY_MX, mxHosts: mxHosts, email: "a@nomx.test", key: "nomx.test"},
+		{name: "lookup failed", by: LIMIT_BY_MX, mxHosts: mxHosts, email: "a@missing.test", key: "missing.test"},
+		{name: "no resolver", by: LIMIT_BY_MX, email: "a@example.com", key: "example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewHostLimiter(HostLimit{By: tt.by, MaxConns: 1}, tt.mxHosts)
+
+			if key := l.hostKey(tt.email); key != tt.key {
+				t.Fatalf("hostKey is %q, want %q", key, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package verifier
+
+import (
+	"database/sql"
+	"email_verify/db"
+	"email_verify/schema"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const (
+	DEFAULT_PROXY_MAX_FAILURES = 5
+	DEFAULT_PROXY_COOLDOWN_MS = 60000
+)
+
+// ProxyFailover takes a proxy out of rotation after MaxFailures
+// consecutive failures to connect through it and brings it back after
+// CooldownMs.
+type ProxyFailover struct {
+	MaxFailures int `json:"maxFailures"`
+	CooldownMs int `json:"cooldownMs"`
+}
+
+type ProxyHealth struct {
+	Successes int `json:"successes"`
+	Failures int `json:"failures"`
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	IsHealthy bool `json:"isHealthy"`
+	DisabledUntil time.Time `json:"disabledUntil"`
+	LastError string `json:"lastError"`
+	LastErrorAt time.Time `json:"lastErrorAt"`
+	LastSuccessAt time.Time `json:"lastSuccessAt"`
+}
+
+func (v *Verifier) initProxyHealth() {
+	v.proxyMu.Lock()
+	defer v.proxyMu.Unlock()
+
+	if len(v.ProxyHealth) == len(v.Proxies) {
+		return
+	}
+
+	v.ProxyHealth = make([]*ProxyHealth, len(v.Proxies))
+	for i := range v.ProxyHealth {
+		v.ProxyHealth[i] = &ProxyHealth{IsHealthy: true}
+	}
+}
+
+// isInRotation brings back proxies whose cooldown is over. proxyMu must
+// be held.
+func (v *Verifier) isInRotation(idx int, now time.Time) bool {
+	h := v.ProxyHealth[idx]
+
+	if !h.IsHealthy && now.After(h.DisabledUntil) {
+		h.IsHealthy = true
+		h.ConsecutiveFailures = 0
+	}
+
+	return h.IsHealthy
+}
+
+// nextHealthyProxy returns the first proxy in rotation starting at from.
+// When every proxy is out it returns the one that comes back first.
+// proxyMu must be held.
+func (v *Verifier) nextHealthyProxy(from int) int {
+	n := len(v.Proxies)
+	now := time.Now()
+
+	for i := 0; i < n; i++ {
+		idx := (from + i) % n
+		if v.isInRotation(idx, now) {
+			return idx
+		}
+	}
+
+	soonest := from % n
+	for i := range v.ProxyHealth {
+		if v.ProxyHealth[i].DisabledUntil.Before(v.ProxyHealth[soonest].DisabledUntil) {
+			soonest = i
+		}
+	}
+
+	return soonest
+}
+
+
+// recordProxyResult counts a connection made through the proxy at idx.
+// Only a *ProxyError counts against it; a mail server that can't be
+// reached through a working proxy doesn't.
+func (v *Verifier) recordProxyResult(idx int, err error) {
+	if idx < 0 {
+		return
+	}
+
+	var proxyErr *ProxyError
+
+	v.proxyMu.Lock()
+
+	h := v.ProxyHealth[idx]
+	now := time.Now()
+	disabled := false
+
+	if errors.As(err, &proxyErr) {
+		h.Failures++
+		h.ConsecutiveFailures++
+		h.LastError = err.Error()
+		h.LastErrorAt = now
+
+		if h.IsHealthy && h.ConsecutiveFailures >= v.ProxyFailover.MaxFailures {
+			h.IsHealthy = false
+			h.DisabledUntil = now.Add(time.Duration(v.ProxyFailover.CooldownMs) * time.Millisecond)
+			disabled = true
+		}
+	} else {
+		h.Successes++
+		h.ConsecutiveFailures = 0
+		h.LastSuccessAt = now
+	}
+
+	v.proxyMu.Unlock()
+
+	if disabled {
+		v.Emit("proxy-disabled", fmt.Sprint(idx))
+		v.saveProxyHealth(true)
+	}
+}
+
+// sameProxy reports whether uri is the proxy p, credentials included.
+func sameProxy(uri string, p schema.ProxyDetails) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	name := u.User.Username()
+	password, _ := u.User.Password()
+
+	return u.Scheme == p.Proto && u.Hostname() == p.Host && u.Port() == p.Port &&
+		name == p.Name && password == p.Password
+}
+
+func nullableTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// saveProxyHealth writes the health of every proxy of the run to the db
+// so it shows up in the proxy list. Proxies that aren't in the list of
+// the run's user have nowhere to show up and are left out.
+func (v *Verifier) saveProxyHealth(isInUse bool) {
+	if len(v.Proxies) == 0 {
+		return
+	}
+
+	proxies, err := db.GetProxyList(v.db, v.UserId)
+	if err != nil {
+		fmt.Println("save proxy health:", err.Error())
+		return
+	}
+
+	v.proxyMu.Lock()
+	list := []schema.ProxyHealth{}
+
+	for i, uri := range v.Proxies {
+		idx := slices.IndexFunc(proxies, func(p schema.ProxyDetails) bool {
+			return sameProxy(uri, p)
+		})
+		if idx == -1 {
+			continue
+		}
+
+		h := v.ProxyHealth[i]
+		list = append(list, schema.ProxyHealth{
+			ProxyId: proxies[idx].Id,
+			IsInUse: isInUse && h.IsHealthy,
+			IsHealthy: h.IsHealthy,
+			SuccessCount: int64(h.Successes),
+			FailureCount: int64(h.Failures),
+			ConsecutiveFailures: int64(h.ConsecutiveFailures),
+			LastError: h.LastError,
+			LastErrorAt: nullableTime(h.LastErrorAt),
+			LastSuccessAt: nullableTime(h.LastSuccessAt),
+		})
+	}
+	v.proxyMu.Unlock()
+
+	for _, h := range list {
+		if err := db.SaveProxyHealth(v.db, h); err != nil {
+			fmt.Println("save proxy health:", err.Error())
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package verifier
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+const (
+	PROXY_PER_BATCH = "per-batch"
+	PROXY_ROUND_ROBIN = "round-robin"
+	PROXY_RANDOM = "random"
+	PROXY_LEAST_RECENTLY_USED = "least-recently-used"
+	PROXY_WEIGHTED = "weighted"
+	PROXY_STICKY_DOMAIN = "sticky-domain"
+)
+
+var ProxyStrategies = []string{
+	PROXY_PER_BATCH,
+	PROXY_ROUND_ROBIN,
+	PROXY_RANDOM,
+	PROXY_LEAST_RECENTLY_USED,
+	PROXY_WEIGHTED,
+	PROXY_STICKY_DOMAIN,
+}
+
+type proxyRotation struct {
+	next     int
+	lastUsed []time.Time
+	sticky   map[string]int
+}
+
+func validateProxyStrategy(opts *VerifierOptions) error {
+	if opts.ProxyStrategy == "" {
+		opts.ProxyStrategy = PROXY_PER_BATCH
+	}
+
+	if !slices.Contains(ProxyStrategies, opts.ProxyStrategy) {
+		return errors.New("unknown proxyStrategy: " + opts.ProxyStrategy)
+	}
+
+	if len(opts.ProxyWeights) != 0 && len(opts.ProxyWeights) != len(opts.Proxies) {
+		return errors.New("proxyWeights must have one weight per proxy.")
+	}
+
+	for _, w := range opts.ProxyWeights {
+		if w < 0 {
+			return errors.New("proxyWeights can't be negative.")
+		}
+	}
+
+	return nil
+}
+
+func (v *Verifier) initProxyRotation() {
+	v.proxyMu.Lock()
+	v.rot = proxyRotation{
+		lastUsed: make([]time.Time, len(v.Proxies)),
+		sticky:   make(map[string]int),
+	}
+	v.InFlightProxies = make(map[string]int)
+	v.proxyMu.Unlock()
+}
+
+// healthyProxies returns the proxies in rotation. proxyMu must be held.
+func (v *Verifier) healthyProxies() []int {
+	now := time.Now()
+	idxs := []int{}
+
+	for i := range v.Proxies {
+		if v.isInRotation(i, now) {
+			idxs = append(idxs, i)
+		}
+	}
+
+	return idxs
+}
+
+func (v *Verifier) proxyWeight(idx int) int {
+	if len(v.ProxyWeights) == 0 {
+		return 1
+	}
+	return v.ProxyWeights[idx]
+}
+
+// pickProxy chooses a proxy for email with the run's strategy. proxyMu
+// must be held.
+func (v *Verifier) pickProxy(email string) int {
+	now := time.Now()
+
+	switch v.ProxyStrategy {
+	case PROXY_ROUND_ROBIN:
+		idx := v.nextHealthyProxy(v.rot.next)
+		v.rot.next = idx + 1
+		return idx
+
+	case PROXY_RANDOM:
+		if healthy := v.healthyProxies(); len(healthy) > 0 {
+			return healthy[rand.Intn(len(healthy))]
+		}
+
+	case PROXY_LEAST_RECENTLY_USED:
+		if healthy := v.healthyProxies(); len(healthy) > 0 {
+			return slices.MinFunc(healthy, func(a, b int) int {
+				return v.rot.lastUsed[a].Compare(v.rot.lastUsed[b])
+			})
+		}
+
+	case PROXY_WEIGHTED:
+		healthy := v.healthyProxies()
+		total := 0
+		for _, i := range healthy {
+			total += v.proxyWeight(i)
+		}
+		if total > 0 {
+			n := rand.Intn(total)
+			for _, i := range healthy {
+				n -= v.proxyWeight(i)
+				if n < 0 {
+					return i
+				}
+			}
+		}
+
+	case PROXY_STICKY_DOMAIN:
+		domain := emailDomain(email)
+		if idx, ok := v.rot.sticky[domain]; ok && v.isInRotation(idx, now) {
+			return idx
+		}
+		idx := v.nextHealthyProxy(v.rot.next)
+		v.rot.next = idx + 1
+		v.rot.sticky[domain] = idx
+		return idx
+
+	default:
+		if v.CurProxyIdx < 0 || !v.isInRotation(v.CurProxyIdx, now) {
+			v.CurProxyIdx = v.nextHealthyProxy(max(v.CurProxyIdx, 0))
+		}
+		return v.CurProxyIdx
+	}
+
+	return v.nextHealthyProxy(0)
+}
+
+// acquireProxy returns the proxy to check email with and marks email as
+// in flight on it until releaseProxy is called.
+func (v *Verifier) acquireProxy(email string) int {
+	if len(v.Proxies) == 0 {
+		return -1
+	}
+
+	v.proxyMu.Lock()
+	defer v.proxyMu.Unlock()
+
+	idx := v.pickProxy(email)
+
+	v.rot.lastUsed[idx] = time.Now()
+	v.InFlightProxies[email] = idx
+
+	return idx
+}
+
+func (v *Verifier) releaseProxy(email string) {
+	v.proxyMu.Lock()
+	delete(v.InFlightProxies, email)
+	v.proxyMu.Unlock()
+}
@@ -0,0 +1,313 @@
+package verifier
+
+import (
+	"database/sql"
+	"email_verify/db"
+	"email_verify/schema"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_GREYLIST_DELAY_MS = 5 * 60 * 1000
+	DEFAULT_GREYLIST_RECHECKS = 3
+	RECHECK_POLL_INTERVAL = 15 * time.Second
+	RECHECK_CONCURRENCY = 10
+)
+
+// GreylistRecheck is how emails answered with greylisting are checked
+// again. Instead of the short retries of the batch they wait DelayMs in
+// the RecheckQueue, up to MaxRechecks times.
+type GreylistRecheck struct {
+	DelayMs int `json:"delayMs"`
+	MaxRechecks int `json:"maxRechecks"`
+}
+
+func (g *GreylistRecheck) validate() error {
+	if g.DelayMs < 0 || g.MaxRechecks < 0 {
+		return errors.New("greylistRecheck values can't be negative.")
+	}
+
+	if g.DelayMs == 0 {
+		g.DelayMs = DEFAULT_GREYLIST_DELAY_MS
+	}
+
+	if g.MaxRechecks == 0 {
+		g.MaxRechecks = DEFAULT_GREYLIST_RECHECKS
+	}
+
+	return nil
+}
+
+// recheckQueue checks greylisted emails again once their wait is over,
+// and verifies single emails outside of a run, such as corrected ones.
+// The queue is kept in the db so it outlives the run that filled it and
+// server restarts.
+type recheckQueue struct {
+	db      *sql.DB
+	pool    *WorkerPool
+	saveSeq int
+	wake    chan struct{}
+	sync.Mutex
+}
+
+var RecheckQueue = recheckQueue{wake: make(chan struct{}, 1)}
+
+// Start begins checking the due emails every RECHECK_POLL_INTERVAL.
+func (q *recheckQueue) Start(dbConn *sql.DB) {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.db != nil {
+		return
+	}
+
+	q.db = dbConn
+	q.pool = NewWorkerPool(RECHECK_CONCURRENCY)
+
+	go func() {
+		t := time.NewTicker(RECHECK_POLL_INTERVAL)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+			case <-q.wake:
+			}
+
+			if err := q.checkDue(); err != nil {
+				fmt.Println("recheck queue:", err.Error())
+			}
+		}
+	}()
+}
+
+// Add queues email of the run of v, which got a greylisting reply
+// through proxy.
+func (q *recheckQueue) Add(v *Verifier, email, proxy, lastError string) error {
+	// runs restored from before the option existed have it unset.
+	g := v.GreylistRecheck
+	if err := g.validate(); err != nil {
+		return err
+	}
+
+	r := schema.EmailRecheck{
+		FileId: v.File.Id,
+		EmailId: email,
+		UserId: v.UserId,
+		Checker: v.Checker,
+		Proxy: proxy,
+		SMTPIdentity: v.UsedSMTPIdentity,
+		Gravatar: v.CheckGravatar,
+		MaxAttempts: g.MaxRechecks,
+		DelayMs: g.DelayMs,
+		LastError: lastError,
+	}
+
+	return db.SaveRecheck(v.db, r, time.Now().Add(time.Duration(r.DelayMs) * time.Millisecond))
+}
+
+// lastRun returns a copy of the run of fileId, from its verifier or from
+// the saved run, or nil when the file has none.
+func lastRun(dbConn *sql.DB, fileId int64) (*Verifier, error) {
+	run := &Verifier{db: dbConn}
+	run.File.Id = fileId
+
+	if v := VerifierManager.Get(fileId); v != nil {
+		run.VerifierData = v.Snapshot()
+		return run, nil
+	}
+
+	r, err := db.GetRun(dbConn, fileId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(r.Data, &run.VerifierData); err != nil {
+		return nil, err
+	}
+	run.setDefaults()
+
+	return run, nil
+}
+
+// recheckProxy returns the proxy the run would check its next email
+// with, or "" when it has no proxies.
+func (v *Verifier) recheckProxy() string {
+	v.initProxyHealth()
+
+	v.proxyMu.Lock()
+	defer v.proxyMu.Unlock()
+
+	if len(v.Proxies) == 0 {
+		return ""
+	}
+
+	return v.Proxies[v.nextHealthyProxy(0)]
+}
+
+// AddEmails queues emails of the file to be verified right away, scored
+// with the weights of userId. They are checked with the checker, proxies,
+// identity and gravatar option of the file's last run, if it had one.
+func (q *recheckQueue) AddEmails(fileId int64, userId string, emails []string) error {
+	q.Lock()
+	dbConn := q.db
+	q.Unlock()
+
+	if dbConn == nil {
+		return errors.New("recheck queue is not started.")
+	}
+
+	r := schema.EmailRecheck{
+		FileId: fileId,
+		UserId: userId,
+		Checker: DefaultChecker(),
+		MaxAttempts: DEFAULT_GREYLIST_RECHECKS,
+		DelayMs: DEFAULT_GREYLIST_DELAY_MS,
+	}
+
+	run, err := lastRun(dbConn, fileId)
+	if err != nil {
+		return err
+	}
+
+	if run != nil {
+		if run.Checker != "" {
+			r.Checker = run.Checker
+		}
+		r.Proxy = run.recheckProxy()
+		r.SMTPIdentity = run.UsedSMTPIdentity
+		r.Gravatar = run.CheckGravatar
+	}
+
+	if r.SMTPIdentity == (schema.SMTPIdentity{}) {
+		var override schema.SMTPIdentity
+		if run != nil {
+			override = run.SMTPIdentity
+		}
+
+		r.SMTPIdentity, err = resolveSMTPIdentity(dbConn, r.UserId, override)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, email := range emails {
+		r.EmailId = email
+
+		if err := db.SaveRecheck(dbConn, r, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (q *recheckQueue) checkDue() error {
+	rechecks, err := db.GetDueRechecks(q.db, time.Now(), 100)
+	if err != nil {
+		return err
+	}
+
+	files := map[int64]struct{}{}
+	wg := sync.WaitGroup{}
+
+	for _, r := range rechecks {
+		files[r.FileId] = struct{}{}
+
+		wg.Add(1)
+		q.pool.Go(func() {
+			defer wg.Done()
+			if err := q.recheck(r); err != nil {
+				fmt.Println("recheck", r.FileId, r.EmailId, ":", err.Error())
+			}
+		})
+	}
+
+	wg.Wait()
+
+	for fileId := range files {
+		q.emitPendingCount(fileId)
+	}
+
+	return nil
+}
+
+// recheck checks r again. A greylisting reply puts it back in the queue
+// until it runs out of attempts; any other result is saved.
+func (q *recheckQueue) recheck(r schema.EmailRecheck) error {
+	lists, err := db.GetDomainListMap(q.db, r.UserId)
+	if err != nil {
+		return err
+	}
+
+	var e schema.EmailDetails
+
+	if list := domainLists(lists).match(r.EmailId); list != "" {
+		e.EmailId = r.EmailId
+		applyDomainList(&e, list)
+	} else {
+		checker, err := NewChecker(r.Checker, CheckerOptions{Proxy: r.Proxy, Identity: r.SMTPIdentity, Gravatar: r.Gravatar})
+		if err != nil {
+			return err
+		}
+
+		e = checkEmail(checker, r.EmailId)
+	}
+
+	e.FileId = r.FileId
+
+	r.Attempts++
+
+	if e.ErrorCode == string(ERR_GREYLISTED) && r.Attempts < r.MaxAttempts {
+		r.LastError = e.ErrorMsg.String
+		return db.SaveRecheck(q.db, r, time.Now().Add(time.Duration(r.DelayMs) * time.Millisecond))
+	}
+
+	weights, err := db.GetScoreWeights(q.db, r.UserId)
+	if err != nil {
+		return err
+	}
+	setScore(&e, weights)
+
+	q.Lock()
+	q.saveSeq++
+	suffix := "recheck_" + strconv.FormatInt(r.FileId, 10) + "_" + strconv.Itoa(q.saveSeq)
+	q.Unlock()
+
+	if err := saveResults(q.db, suffix, strings.TrimSuffix(e.ToCSVLn(), "\n")); err != nil {
+		return err
+	}
+
+	return db.DeleteRecheck(q.db, r.FileId, r.EmailId)
+}
+
+// emitPendingCount tells the socket of the file's verifier how many
+// emails are still waiting for a recheck.
+func (q *recheckQueue) emitPendingCount(fileId int64) {
+	v := VerifierManager.Get(fileId)
+	if v == nil {
+		return
+	}
+
+	count, err := db.GetPendingRecheckCount(q.db, fileId)
+	if err != nil {
+		fmt.Println("recheck queue:", err.Error())
+		return
+	}
+
+	v.Emit("pending-recheck-count", strconv.FormatInt(count, 10))
+}
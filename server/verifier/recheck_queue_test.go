@@ -0,0 +1,35 @@
+package verifier
+
+import (
+	"email_verify/schema"
+	"testing"
+	"time"
+)
+
+// TestLastRunOptions checks that emails checked outside of a run get the
+// options of the file's verifier.
+func TestLastRunOptions(t *testing.T) {
+	v := newTestVerifier(t, 1)
+	v.Proxies = []string{"socks5://a:1080", "socks5://b:1080"}
+	v.CheckGravatar = true
+	v.UsedSMTPIdentity = schema.SMTPIdentity{HeloName: "mail.example.org"}
+	v.initProxyHealth()
+	v.ProxyHealth[0].IsHealthy = false
+	v.ProxyHealth[0].DisabledUntil = time.Now().Add(time.Hour)
+
+	VerifierManager.Add(v.File.Id, v)
+	t.Cleanup(func() { VerifierManager.Remove(v.File.Id) })
+
+	run, err := lastRun(nil, v.File.Id)
+	if err != nil || run == nil {
+		t.Fatalf("lastRun returned %v, %v", run, err)
+	}
+
+	if p := run.recheckProxy(); p != "socks5://b:1080" {
+		t.Errorf("proxy is %q, want the healthy one", p)
+	}
+
+	if !run.CheckGravatar || run.UsedSMTPIdentity != v.UsedSMTPIdentity || run.Checker != RACE_CHECKER {
+		t.Errorf("options of the run weren't kept: %+v", run.VerifierOptions)
+	}
+}
@@ -0,0 +1,123 @@
+package verifier
+
+import (
+	"email_verify/schema"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_FLUSH_INTERVAL_MS = 3000
+	DEFAULT_FLUSH_SIZE = 100
+)
+
+// resultWriter saves finished results to the db while the batch is still
+// running. Results are written in groups, every FlushIntervalMs or as
+// soon as FlushSize of them are waiting, whichever comes first.
+type resultWriter struct {
+	v        *Verifier
+	size     int
+	interval time.Duration
+	pending  []schema.EmailDetails
+	flushId  int
+	kick     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	mu       sync.Mutex
+	flushMu  sync.Mutex
+}
+
+func newResultWriter(v *Verifier) *resultWriter {
+	w := &resultWriter{
+		v: v,
+		size: v.FlushSize,
+		interval: time.Duration(v.FlushIntervalMs) * time.Millisecond,
+		kick: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go w.loop()
+
+	return w
+}
+
+func (w *resultWriter) loop() {
+	defer close(w.done)
+
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-w.kick:
+		case <-w.stop:
+			return
+		}
+
+		if err := w.Flush(); err != nil {
+			fmt.Println("result writer:", err.Error())
+		}
+	}
+}
+
+// add queues a finished result to be written.
+func (w *resultWriter) add(e schema.EmailDetails) {
+	setScore(&e, w.v.scoreWeights)
+
+	w.mu.Lock()
+	w.pending = append(w.pending, e)
+	full := len(w.pending) >= w.size
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes the waiting results. On error they are kept so the next
+// flush tries them again.
+func (w *resultWriter) Flush() error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	csvStr := ""
+	for i := range pending {
+		csvStr += pending[i].ToCSVLn()
+	}
+
+	w.flushId++
+	if err := w.v.updateCsvStrToDB(strings.TrimSuffix(csvStr, "\n"), w.flushId); err != nil {
+		w.mu.Lock()
+		w.pending = append(pending, w.pending...)
+		w.mu.Unlock()
+		return err
+	}
+
+	w.v.Emit("results-flushed", strconv.Itoa(len(pending)))
+
+	return nil
+}
+
+// Close stops the background flushes and writes what is left.
+func (w *resultWriter) Close() error {
+	close(w.stop)
+	<-w.done
+	return w.Flush()
+}
@@ -0,0 +1,131 @@
+package verifier
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+const (
+	// DEFAULT_MAX_RETRY_DELAY_MS caps the delays of a policy without a
+	// MaxDelayMs, which would otherwise grow past what time.Duration holds.
+	DEFAULT_MAX_RETRY_DELAY_MS = 60 * 60 * 1000
+	// MAX_RETRY_DELAY_MS is the longest MaxDelayMs a policy can have.
+	MAX_RETRY_DELAY_MS = 24 * 60 * 60 * 1000
+)
+
+// RetryRule overrides the policy for a single error code. A MaxRetries
+// of 0 means emails failing with the code are not retried.
+type RetryRule struct {
+	MaxRetries int `json:"maxRetries"`
+	BaseDelayMs int `json:"baseDelayMs"`
+}
+
+// RetryPolicy decides which failed emails are checked again and how long
+// to wait before each attempt. The wait for attempt n is
+// BaseDelayMs * Multiplier^(n-1), capped at MaxDelayMs (or
+// DEFAULT_MAX_RETRY_DELAY_MS when it is 0) and spread by +/- Jitter (a
+// fraction of the delay).
+type RetryPolicy struct {
+	MaxRetries int `json:"maxRetries"`
+	BaseDelayMs int `json:"baseDelayMs"`
+	MaxDelayMs int `json:"maxDelayMs"`
+	Multiplier float64 `json:"multiplier"`
+	Jitter float64 `json:"jitter"`
+	Rules map[ErrorCode]RetryRule `json:"rules"`
+}
+
+// legacyRetryPolicy retries the retryable errors retryCount times with a
+// fixed delay, which is how runs created without a policy behave.
+func legacyRetryPolicy(retryCount, delayMs int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: retryCount,
+		BaseDelayMs: delayMs,
+		Multiplier: 1,
+		Rules: map[ErrorCode]RetryRule{},
+	}
+}
+
+func (p *RetryPolicy) validate() error {
+	if p.MaxRetries < 0 || p.BaseDelayMs < 0 || p.MaxDelayMs < 0 {
+		return errors.New("retryPolicy values can't be negative.")
+	}
+
+	if p.MaxDelayMs > MAX_RETRY_DELAY_MS {
+		return errors.New("retryPolicy.maxDelayMs can't be more than a day.")
+	}
+
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return errors.New("retryPolicy.jitter must be between 0 and 1.")
+	}
+
+	if p.Multiplier <= 0 {
+		p.Multiplier = 2
+	}
+
+	if p.Rules == nil {
+		p.Rules = map[ErrorCode]RetryRule{}
+	}
+
+	for code, rule := range p.Rules {
+		if !slices.Contains(ErrorCodes, code) {
+			return errors.New("retryPolicy has a rule for unknown error code: " + string(code))
+		}
+		if rule.MaxRetries < 0 || rule.BaseDelayMs < 0 {
+			return errors.New("retryPolicy rule values can't be negative.")
+		}
+	}
+
+	return nil
+}
+
+// retries returns how many times an email failing with code is retried.
+func (p *RetryPolicy) retries(code ErrorCode) int {
+	if rule, ok := p.Rules[code]; ok {
+		return rule.MaxRetries
+	}
+
+	if code.IsRetryable() {
+		return p.MaxRetries
+	}
+
+	return 0
+}
+
+// maxRetries is the number of retry passes a batch can need.
+func (p *RetryPolicy) maxRetries() int {
+	n := p.MaxRetries
+	for _, rule := range p.Rules {
+		n = max(n, rule.MaxRetries)
+	}
+	return n
+}
+
+func (p *RetryPolicy) ShouldRetry(code ErrorCode, attempt int) bool {
+	return attempt <= p.retries(code)
+}
+
+// Delay returns how long to wait before the given attempt (starting at 1)
+// of an email that failed with code.
+func (p *RetryPolicy) Delay(code ErrorCode, attempt int) time.Duration {
+	base := p.BaseDelayMs
+	if rule, ok := p.Rules[code]; ok && rule.BaseDelayMs > 0 {
+		base = rule.BaseDelayMs
+	}
+
+	d := float64(base) * math.Pow(p.Multiplier, float64(attempt-1))
+
+	maxDelay := p.MaxDelayMs
+	if maxDelay <= 0 {
+		maxDelay = DEFAULT_MAX_RETRY_DELAY_MS
+	}
+	d = math.Min(d, float64(maxDelay))
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(d) * time.Millisecond
+}
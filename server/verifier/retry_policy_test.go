@@ -0,0 +1,114 @@
+package verifier
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		policy RetryPolicy
+		ok bool
+	}{
+		{name: "empty", policy: RetryPolicy{}, ok: true},
+		{name: "negative retries", policy: RetryPolicy{MaxRetries: -1}},
+		{name: "negative delay", policy: RetryPolicy{BaseDelayMs: -1}},
+		{name: "max delay over a day", policy: RetryPolicy{MaxDelayMs: MAX_RETRY_DELAY_MS + 1}},
+		{name: "max delay of a day", policy: RetryPolicy{MaxDelayMs: MAX_RETRY_DELAY_MS}, ok: true},
+		{name: "jitter over 1", policy: RetryPolicy{Jitter: 1.5}},
+		{name: "negative jitter", policy: RetryPolicy{Jitter: -0.1}},
+		{name: "rule", policy: RetryPolicy{Rules: map[ErrorCode]RetryRule{ERR_GREYLISTED: {MaxRetries: 2}}}, ok: true},
+		{name: "rule for unknown code", policy: RetryPolicy{Rules: map[ErrorCode]RetryRule{"nope": {MaxRetries: 1}}}},
+		{name: "negative rule", policy: RetryPolicy{Rules: map[ErrorCode]RetryRule{ERR_DNS: {MaxRetries: -1}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.validate()
+			if (err == nil) != tt.ok {
+				t.Fatalf("validate returned %v, want ok %v", err, tt.ok)
+			}
+
+			if err == nil && (tt.policy.Multiplier != 2 || tt.policy.Rules == nil) {
+				t.Fatalf("defaults weren't set: %+v", tt.policy)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyRetries(t *testing.T) {
+	p := RetryPolicy{
+		MaxRetries: 2,
+		Rules: map[ErrorCode]RetryRule{
+			ERR_DNS: {MaxRetries: 1},
+			ERR_PROXY: {MaxRetries: 0},
+			ERR_GREYLISTED: {MaxRetries: 4},
+		},
+	}
+
+	tests := []struct {
+		code ErrorCode
+		attempt int
+		retry bool
+	}{
+		{ERR_CONNECT_TIMEOUT, 2, true},
+		{ERR_CONNECT_TIMEOUT, 3, false},
+		{ERR_SMTP_5XX, 1, false},
+		{ERR_DNS, 1, true},
+		{ERR_DNS, 2, false},
+		{ERR_PROXY, 1, false},
+		{ERR_GREYLISTED, 4, true},
+	}
+
+	for _, tt := range tests {
+		if r := p.ShouldRetry(tt.code, tt.attempt); r != tt.retry {
+			t.Errorf("ShouldRetry(%q, %d) is %v, want %v", tt.code, tt.attempt, r, tt.retry)
+		}
+	}
+
+	if n := p.maxRetries(); n != 4 {
+		t.Errorf("maxRetries is %d, want 4", n)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name string
+		policy RetryPolicy
+		code ErrorCode
+		attempt int
+		delay time.Duration
+	}{
+		{name: "first", policy: RetryPolicy{BaseDelayMs: 1000, Multiplier: 2}, attempt: 1, delay: time.Second},
+		{name: "backoff", policy: RetryPolicy{BaseDelayMs: 1000, Multiplier: 2}, attempt: 4, delay: 8 * time.Second},
+		{name: "capped", policy: RetryPolicy{BaseDelayMs: 1000, Multiplier: 2, MaxDelayMs: 5000}, attempt: 4, delay: 5 * time.Second},
+		{name: "no max delay", policy: RetryPolicy{BaseDelayMs: 1000, Multiplier: 10}, attempt: 100, delay: DEFAULT_MAX_RETRY_DELAY_MS * time.Millisecond},
+		{name: "fixed", policy: *legacyRetryPolicy(3, 500), attempt: 3, delay: 500 * time.Millisecond},
+		{
+			name: "rule delay",
+			policy: RetryPolicy{BaseDelayMs: 1000, Multiplier: 2, Rules: map[ErrorCode]RetryRule{ERR_GREYLISTED: {BaseDelayMs: 60000}}},
+			code: ERR_GREYLISTED,
+			attempt: 2,
+			delay: 2 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := tt.policy.Delay(tt.code, tt.attempt); d != tt.delay {
+				t.Fatalf("Delay is %v, want %v", d, tt.delay)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	p := RetryPolicy{BaseDelayMs: 1000, Multiplier: 1, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		if d := p.Delay(ERR_CONNECT_TIMEOUT, 1); d < 500 * time.Millisecond || d > 1500 * time.Millisecond {
+			t.Fatalf("Delay is %v, want it within 50%% of 1s", d)
+		}
+	}
+}
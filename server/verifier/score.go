@@ -0,0 +1,83 @@
+package verifier
+
+import "email_verify/schema"
+
+// Score rates how safe it is to send to e, from 0 to 100, and puts it in
+// a category. Emails that can't exist are invalid with a score of 0.
+// Errors that leave the mailbox unchecked make it unknown. Emails settled
+// by a domain list are scored by the list alone. Disposable addresses
+// whose mailbox wasn't checked are risky.
+func Score(e *schema.EmailDetails, w schema.ScoreWeights) (int, string) {
+	switch e.MatchedList {
+	case schema.LIST_TRUSTED:
+		return 100, schema.CATEGORY_SAFE
+	case schema.LIST_BLOCKED:
+		return 0, schema.CATEGORY_INVALID
+	case schema.LIST_DISPOSABLE:
+		return max(0, 100 - w.Disposable), schema.CATEGORY_RISKY
+	}
+
+	code := ErrorCode(e.ErrorCode)
+
+	if code == ERR_DNS || code == ERR_SMTP_5XX {
+		return 0, schema.CATEGORY_INVALID
+	}
+
+	score := 100
+	category := ""
+
+	// the other errors come before the syntax is known when the checker
+	// couldn't be made.
+	if code != ERR_NONE {
+		score = w.Unknown
+		category = schema.CATEGORY_UNKNOWN
+	} else if e.IsValidSyntax && e.IsDisposable && !e.IsHostExists {
+		// the mail server of a disposable address isn't asked, so only
+		// the address itself is scored.
+		category = schema.CATEGORY_RISKY
+	} else if !e.IsValidSyntax || !e.IsHostExists || !e.HasMxRecords {
+		return 0, schema.CATEGORY_INVALID
+	} else if !e.IsDeliverable && !e.IsCatchAll {
+		if e.Reachable == "unknown" {
+			score = w.Unknown
+			category = schema.CATEGORY_UNKNOWN
+		} else {
+			return 0, schema.CATEGORY_INVALID
+		}
+	}
+
+	if e.IsCatchAll {
+		score -= w.CatchAll
+	}
+	if e.IsDisposable {
+		score -= w.Disposable
+	}
+	if e.IsRoleAccount {
+		score -= w.RoleAccount
+	}
+	if e.IsFree {
+		score -= w.Free
+	}
+	if e.IsInboxFull {
+		score -= w.InboxFull
+	}
+	if e.HasGravatar {
+		score += w.Gravatar
+	}
+
+	score = max(0, min(100, score))
+
+	if category == "" {
+		if score >= w.SafeThreshold {
+			category = schema.CATEGORY_SAFE
+		} else {
+			category = schema.CATEGORY_RISKY
+		}
+	}
+
+	return score, category
+}
+
+func setScore(e *schema.EmailDetails, w schema.ScoreWeights) {
+	e.Score, e.Category = Score(e, w)
+}
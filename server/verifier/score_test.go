@@ -0,0 +1,70 @@
+package verifier
+
+import (
+	"email_verify/schema"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	w := schema.DefaultScoreWeights()
+
+	deliverable := func(change func(e *schema.EmailDetails)) schema.EmailDetails {
+		e := schema.EmailDetails{
+			IsValidSyntax: true,
+			HasMxRecords: true,
+			IsHostExists: true,
+			IsDeliverable: true,
+			Reachable: "yes",
+		}
+		change(&e)
+		return e
+	}
+
+	tests := []struct {
+		name string
+		e schema.EmailDetails
+		score int
+		category string
+	}{
+		{name: "deliverable", e: deliverable(func(e *schema.EmailDetails) {}), score: 100, category: schema.CATEGORY_SAFE},
+		{name: "free with gravatar", e: deliverable(func(e *schema.EmailDetails) { e.IsFree = true; e.HasGravatar = true }), score: 100, category: schema.CATEGORY_SAFE},
+		{name: "role account", e: deliverable(func(e *schema.EmailDetails) { e.IsRoleAccount = true }), score: 80, category: schema.CATEGORY_SAFE},
+		{name: "role and free", e: deliverable(func(e *schema.EmailDetails) { e.IsRoleAccount = true; e.IsFree = true }), score: 75, category: schema.CATEGORY_RISKY},
+		{name: "catch-all", e: deliverable(func(e *schema.EmailDetails) { e.IsDeliverable = false; e.IsCatchAll = true; e.Reachable = "unknown" }), score: 60, category: schema.CATEGORY_RISKY},
+		{name: "inbox full", e: deliverable(func(e *schema.EmailDetails) { e.IsInboxFull = true }), score: 50, category: schema.CATEGORY_RISKY},
+		{name: "penalties floor at 0", e: deliverable(func(e *schema.EmailDetails) { e.IsCatchAll = true; e.IsDisposable = true; e.IsInboxFull = true }), score: 0, category: schema.CATEGORY_RISKY},
+		{name: "disposable, unchecked", e: deliverable(func(e *schema.EmailDetails) { e.IsDisposable = true; e.IsHostExists = false; e.IsDeliverable = false }), score: 40, category: schema.CATEGORY_RISKY},
+		{name: "undeliverable", e: deliverable(func(e *schema.EmailDetails) { e.IsDeliverable = false; e.Reachable = "no" }), score: 0, category: schema.CATEGORY_INVALID},
+		{name: "mailbox unknown", e: deliverable(func(e *schema.EmailDetails) { e.IsDeliverable = false; e.Reachable = "unknown" }), score: 50, category: schema.CATEGORY_UNKNOWN},
+		{name: "bad syntax", e: deliverable(func(e *schema.EmailDetails) { e.IsValidSyntax = false }), score: 0, category: schema.CATEGORY_INVALID},
+		{name: "no mx", e: deliverable(func(e *schema.EmailDetails) { e.HasMxRecords = false }), score: 0, category: schema.CATEGORY_INVALID},
+		{name: "dns error", e: deliverable(func(e *schema.EmailDetails) { e.ErrorCode = string(ERR_DNS) }), score: 0, category: schema.CATEGORY_INVALID},
+		{name: "5xx", e: deliverable(func(e *schema.EmailDetails) { e.ErrorCode = string(ERR_SMTP_5XX) }), score: 0, category: schema.CATEGORY_INVALID},
+		{name: "greylisted", e: schema.EmailDetails{ErrorCode: string(ERR_GREYLISTED)}, score: 50, category: schema.CATEGORY_UNKNOWN},
+		{name: "timeout, role account", e: schema.EmailDetails{ErrorCode: string(ERR_CONNECT_TIMEOUT), IsRoleAccount: true}, score: 30, category: schema.CATEGORY_UNKNOWN},
+		{name: "trusted list", e: schema.EmailDetails{MatchedList: schema.LIST_TRUSTED}, score: 100, category: schema.CATEGORY_SAFE},
+		{name: "blocked list", e: deliverable(func(e *schema.EmailDetails) { e.MatchedList = schema.LIST_BLOCKED }), score: 0, category: schema.CATEGORY_INVALID},
+		{name: "disposable list", e: schema.EmailDetails{MatchedList: schema.LIST_DISPOSABLE}, score: 40, category: schema.CATEGORY_RISKY},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, category := Score(&tt.e, w)
+			if score != tt.score || category != tt.category {
+				t.Fatalf("Score is %d %s, want %d %s", score, category, tt.score, tt.category)
+			}
+		})
+	}
+}
+
+func TestScoreWeights(t *testing.T) {
+	e := schema.EmailDetails{IsValidSyntax: true, HasMxRecords: true, IsHostExists: true, IsDeliverable: true, IsFree: true}
+
+	w := schema.DefaultScoreWeights()
+	w.Free = 30
+	w.SafeThreshold = 60
+
+	if score, category := Score(&e, w); score != 70 || category != schema.CATEGORY_SAFE {
+		t.Fatalf("Score is %d %s, want 70 %s", score, category, schema.CATEGORY_SAFE)
+	}
+}
@@ -0,0 +1,261 @@
+package verifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"email_verify/db"
+	"email_verify/schema"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"time"
+
+	emailverifier "github.com/AfterShip/email-verifier"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	SMTP_HELLO_NAME = "localhost"
+	SMTP_FROM_EMAIL = "user@example.org"
+	SMTP_CONNECT_TIMEOUT = 10 * time.Second
+	SMTP_OPERATION_TIMEOUT = 10 * time.Second
+)
+
+// ProxyError is returned when the proxy itself failed: it couldn't be
+// reached or refused the handshake. Failures to reach the mail server
+// through the proxy are returned as they are.
+type ProxyError struct {
+	Err error
+}
+
+func (e *ProxyError) Error() string {
+	return "proxy: " + e.Err.Error()
+}
+
+func (e *ProxyError) Unwrap() error {
+	return e.Err
+}
+
+// proxyForward dials the proxy itself for the SOCKS dialer, so that its
+// failures can be told apart from those of the mail server.
+type proxyForward struct{}
+
+func (f proxyForward) Dial(network, addr string) (net.Conn, error) {
+	return f.DialContext(context.Background(), network, addr)
+}
+
+func (proxyForward) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d := net.Dialer{}
+
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, &ProxyError{Err: err}
+	}
+
+	return conn, nil
+}
+
+// proxyDialError blames the proxy for err unless it is about the mail
+// server: a timeout while the proxy connects to it, or one of the SOCKS
+// replies saying it can't be reached.
+func proxyDialError(err error) error {
+	var proxyErr *ProxyError
+	if errors.As(err, &proxyErr) {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	if containsAny(err.Error(), "network unreachable", "host unreachable", "connection refused", "ttl expired") {
+		return err
+	}
+
+	return &ProxyError{Err: err}
+}
+
+// dialSMTP connects to port 25 of host, through proxyURI if it is set.
+func dialSMTP(host, proxyURI string) (*smtp.Client, error) {
+	addr := net.JoinHostPort(host, "25")
+
+	var conn net.Conn
+	var err error
+
+	if proxyURI != "" {
+		u, err := url.Parse(proxyURI)
+		if err != nil {
+			return nil, &ProxyError{Err: err}
+		}
+
+		dialer, err := proxy.FromURL(u, proxyForward{})
+		if err != nil {
+			return nil, &ProxyError{Err: err}
+		}
+
+		ctxDialer, ok := dialer.(proxy.ContextDialer)
+		if !ok {
+			return nil, &ProxyError{Err: errors.New("proxy dialer doesn't support timeouts.")}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), SMTP_CONNECT_TIMEOUT)
+		defer cancel()
+
+		conn, err = ctxDialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, proxyDialError(err)
+		}
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, SMTP_CONNECT_TIMEOUT)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(SMTP_OPERATION_TIMEOUT)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// smtpError wraps err the way emailverifier does, without returning a
+// nil *LookupError for replies it doesn't consider errors.
+func smtpError(err error) error {
+	if e := emailverifier.ParseSMTPError(err); e != nil {
+		return e
+	}
+	return err
+}
+
+// isPermanent reports whether err is a 5xx SMTP reply.
+func isPermanent(err error) bool {
+	var tpErr *textproto.Error
+	return errors.As(err, &tpErr) && tpErr.Code >= 500
+}
+
+// isTemporary reports whether err is a 4xx SMTP reply.
+func isTemporary(err error) bool {
+	var tpErr *textproto.Error
+	return errors.As(err, &tpErr) && tpErr.Code >= 400 && tpErr.Code < 500
+}
+
+// probeEmail returns the address that finds out whether domain is a
+// catch-all. It is made up but the same for every check of the domain,
+// so a server that greylisted it lets it through once the delay is over.
+func probeEmail(domain string, identity schema.SMTPIdentity) string {
+	if identity.ProbeUsername != "" {
+		return identity.ProbeUsername + "@" + domain
+	}
+
+	sum := sha256.Sum256([]byte(domain))
+	return hex.EncodeToString(sum[:16]) + "@" + domain
+}
+
+// smtpCheck asks the mail server of domain whether username exists. When
+// probe is set the probe address of the domain is sent first to find
+// catch-all domains.
+// Unlike emailverifier.CheckSMTP, temporary failures of RCPT TO are
+// returned as errors so that greylisting isn't mistaken for a catch-all
+// domain or a missing mailbox. A temporary failure of the probe leaves
+// the domain's catch-all status unknown and the email is checked anyway;
+// probed is only true when the probe was accepted or refused for good.
+// dialed, when set, is told the outcome of each connection made through
+// proxyURI.
+func smtpCheck(mxHosts []string, domain, username string, probe bool, proxyURI string, dialed func(error), identity schema.SMTPIdentity) (ret *emailverifier.SMTP, probed bool, err error) {
+	identity = schema.SMTPIdentity{HeloName: SMTP_HELLO_NAME, FromEmail: SMTP_FROM_EMAIL}.Override(identity)
+
+	if len(mxHosts) == 0 {
+		return nil, false, smtpError(errors.New("No MX records found"))
+	}
+
+	var client *smtp.Client
+
+	for _, host := range mxHosts {
+		client, err = dialSMTP(host, proxyURI)
+		if proxyURI != "" && dialed != nil {
+			dialed(err)
+		}
+		if err == nil {
+			break
+		}
+	}
+
+	// a proxy error is kept as is so that the run can tell it apart.
+	var proxyErr *ProxyError
+	if errors.As(err, &proxyErr) {
+		return nil, false, err
+	}
+
+	if err != nil {
+		return nil, false, smtpError(err)
+	}
+
+	defer client.Close()
+
+	if err := client.Hello(identity.HeloName); err != nil {
+		return nil, false, smtpError(err)
+	}
+
+	if err := client.Mail(identity.FromEmail); err != nil {
+		return nil, false, smtpError(err)
+	}
+
+	ret = &emailverifier.SMTP{HostExists: true}
+
+	if probe {
+		err := client.Rcpt(probeEmail(domain, identity))
+		if err == nil {
+			ret.CatchAll = true
+			return ret, true, nil
+		}
+		probed = isPermanent(err)
+	}
+
+	err = client.Rcpt(username + "@" + domain)
+	if err == nil {
+		ret.Deliverable = true
+		return ret, probed, nil
+	}
+
+	if lookupErr := emailverifier.ParseSMTPError(err); lookupErr != nil {
+		switch lookupErr.Message {
+		case emailverifier.ErrFullInbox:
+			ret.FullInbox = true
+			return ret, probed, nil
+		case emailverifier.ErrNotAllowed:
+			ret.Disabled = true
+		}
+	}
+
+	if isTemporary(err) {
+		return ret, probed, smtpError(err)
+	}
+
+	return ret, probed, nil
+}
+
+// resolveSMTPIdentity returns the identity the checks of userId are made
+// with: the defaults, replaced by the user's profile and then by override.
+func resolveSMTPIdentity(dbConn *sql.DB, userId string, override schema.SMTPIdentity) (schema.SMTPIdentity, error) {
+	p, err := db.GetUserProfile(dbConn, userId)
+	if err != nil {
+		return schema.SMTPIdentity{}, err
+	}
+
+	identity := schema.SMTPIdentity{HeloName: SMTP_HELLO_NAME, FromEmail: SMTP_FROM_EMAIL}
+
+	return identity.Override(p.SMTPIdentity).Override(override), nil
+}
@@ -0,0 +1,86 @@
+package verifier
+
+import (
+	"maps"
+)
+
+// Snapshot returns a copy of the verifier data that is safe to read and
+// marshal while the run keeps going.
+func (v *Verifier) Snapshot() VerifierData {
+	d := VerifierData{State: v.GetState()}
+
+	v.mu.RLock()
+	d.Priority = v.Priority
+	d.StartedAt = v.StartedAt
+	d.VerifierOptions = v.VerifierOptions
+	d.UsedSMTPIdentity = v.UsedSMTPIdentity
+	d.CurrentBatchNumber = v.CurrentBatchNumber
+	d.CurrentBatchSize = v.CurrentBatchSize
+	d.CurrentProgressList = snapshotProgressList(v.CurrentProgressList)
+
+	if v.CompletedBatches != nil {
+		d.CompletedBatches = make(map[int][]*ProgressData, len(v.CompletedBatches))
+		for n, l := range v.CompletedBatches {
+			d.CompletedBatches[n] = snapshotProgressList(l)
+		}
+	}
+	v.mu.RUnlock()
+
+	v.proxyMu.Lock()
+	d.CurProxyIdx = v.CurProxyIdx
+	if v.ProxyHealth != nil {
+		d.ProxyHealth = make([]*ProxyHealth, len(v.ProxyHealth))
+		for i, h := range v.ProxyHealth {
+			c := *h
+			d.ProxyHealth[i] = &c
+		}
+	}
+	d.InFlightProxies = maps.Clone(v.InFlightProxies)
+	v.proxyMu.Unlock()
+
+	return d
+}
+
+// GetState returns the state of the run.
+func (v *Verifier) GetState() string {
+	v.ctrl.Lock()
+	defer v.ctrl.Unlock()
+	return v.State
+}
+
+// Snapshot returns a copy of the progress taken under its lock.
+func (p *ProgressData) Snapshot() *ProgressData {
+	p.Lock()
+	defer p.Unlock()
+
+	return &ProgressData{
+		Total: p.Total,
+		Progress: p.Progress,
+		Success: p.Success,
+		Failed: p.Failed,
+		Retry: p.Retry,
+		Deferred: p.Deferred,
+		InFlight: p.InFlight,
+	}
+}
+
+func snapshotProgressList(l []*ProgressData) []*ProgressData {
+	if l == nil {
+		return nil
+	}
+
+	c := make([]*ProgressData, len(l))
+	for i, p := range l {
+		c[i] = p.Snapshot()
+	}
+
+	return c
+}
+
+// currentProgress returns the progress of the pass that is running: the
+// batch itself or its latest retry.
+func (v *Verifier) currentProgress() *ProgressData {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.CurrentProgressList[len(v.CurrentProgressList) - 1]
+}
@@ -1,18 +1,66 @@
 package verifier
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+const DEFAULT_MAX_RUNNING = 2
+
+// verifierManager holds the verifier of every file and runs the queued
+// ones, at most maxRunning at a time across all files.
 type verifierManager struct {
 	running map[int64]*Verifier
+	queue []int64
+	active int
+	maxRunning int
+	sync.Mutex
+}
+
+type QueuedJob struct {
+	FileId int64 `json:"fileId"`
+	Priority int `json:"priority"`
+	Position int `json:"position"`
 }
 
 func (vm *verifierManager) Add(fileId int64, v *Verifier) {
+	vm.Lock()
 	vm.running[fileId] = v
+	vm.Unlock()
 }
 
-func (vm *verifierManager) Remove(fileId int64) {
-	delete(vm.running, fileId)
+// Remove cancels the verifier of fileId and forgets it once its run has
+// stopped, so that nothing it still writes outlives it and no second run
+// can start on the file meanwhile. It returns false when a new verifier
+// took its place while it was stopping.
+func (vm *verifierManager) Remove(fileId int64) bool {
+	v := vm.Get(fileId)
+	if v == nil {
+		return true
+	}
+
+	v.Cancel()
+	v.Wait()
+
+	vm.Lock()
+	removed := vm.running[fileId] == v
+	if removed {
+		delete(vm.running, fileId)
+		vm.removeFromQueue(fileId)
+	}
+	vm.Unlock()
+
+	vm.schedule()
+
+	return removed
 }
 
 func (vm *verifierManager) Get(fileId int64) (*Verifier) {
+	vm.Lock()
+	defer vm.Unlock()
+
 	v, ok := vm.running[fileId]
 	if !ok {
 		return nil
@@ -20,4 +68,206 @@ func (vm *verifierManager) Get(fileId int64) (*Verifier) {
 	return v
 }
 
-var VerifierManager verifierManager = verifierManager{running: make(map[int64]*Verifier)}
+func (vm *verifierManager) SetMaxRunning(n int) {
+	if n <= 0 {
+		return
+	}
+
+	vm.Lock()
+	vm.maxRunning = n
+	vm.Unlock()
+
+	vm.schedule()
+}
+
+func (vm *verifierManager) removeFromQueue(fileId int64) bool {
+	i := slices.Index(vm.queue, fileId)
+	if i == -1 {
+		return false
+	}
+	vm.queue = slices.Delete(vm.queue, i, i+1)
+	return true
+}
+
+// Enqueue queues the verifier of fileId to run once a slot is free.
+// Jobs with a higher priority run first, equal priorities in the order
+// they were queued.
+func (vm *verifierManager) Enqueue(fileId int64, priority int) error {
+	vm.Lock()
+
+	v, ok := vm.running[fileId]
+	if !ok {
+		vm.Unlock()
+		return errors.New("verifier not found.")
+	}
+
+	switch v.GetState() {
+	case RUNNING, PAUSED, QUEUED:
+		vm.Unlock()
+		return errors.New("verifier is already running or queued.")
+	case INTERRUPTED:
+		v.interrupted = true
+	}
+
+	v.mu.Lock()
+	v.Priority = priority
+	v.mu.Unlock()
+	v.setState(QUEUED)
+
+	i := len(vm.queue)
+	for i > 0 && vm.running[vm.queue[i-1]].Priority < priority {
+		i--
+	}
+	vm.queue = slices.Insert(vm.queue, i, fileId)
+
+	vm.Unlock()
+
+	v.checkpoint()
+	vm.schedule()
+
+	return nil
+}
+
+// Cancel cancels v and takes it off the queue if it was waiting there, so
+// that it no longer holds a position.
+func (vm *verifierManager) Cancel(v *Verifier) error {
+	if err := v.Cancel(); err != nil {
+		return err
+	}
+
+	vm.Lock()
+	removed := vm.removeFromQueue(v.File.Id)
+	vm.Unlock()
+
+	if removed {
+		vm.emitPositions()
+	}
+
+	return nil
+}
+
+// Move puts a queued job at position (starting at 0) of the queue.
+func (vm *verifierManager) Move(fileId int64, position int) error {
+	vm.Lock()
+
+	if !vm.removeFromQueue(fileId) {
+		vm.Unlock()
+		return errors.New("job is not queued.")
+	}
+
+	position = max(0, min(position, len(vm.queue)))
+	vm.queue = slices.Insert(vm.queue, position, fileId)
+
+	vm.Unlock()
+
+	vm.emitPositions()
+
+	return nil
+}
+
+// SetPriority changes the priority of a queued job and moves it behind
+// the jobs with the same or a higher priority.
+func (vm *verifierManager) SetPriority(fileId int64, priority int) error {
+	vm.Lock()
+
+	if !vm.removeFromQueue(fileId) {
+		vm.Unlock()
+		return errors.New("job is not queued.")
+	}
+
+	v := vm.running[fileId]
+	v.mu.Lock()
+	v.Priority = priority
+	v.mu.Unlock()
+
+	i := len(vm.queue)
+	for i > 0 && vm.running[vm.queue[i-1]].Priority < priority {
+		i--
+	}
+	vm.queue = slices.Insert(vm.queue, i, fileId)
+
+	vm.Unlock()
+
+	vm.emitPositions()
+
+	return nil
+}
+
+// Position returns where fileId is in the queue, or -1.
+func (vm *verifierManager) Position(fileId int64) int {
+	vm.Lock()
+	defer vm.Unlock()
+	return slices.Index(vm.queue, fileId)
+}
+
+func (vm *verifierManager) Queue() []QueuedJob {
+	vm.Lock()
+	defer vm.Unlock()
+
+	jobs := make([]QueuedJob, len(vm.queue))
+	for i, fileId := range vm.queue {
+		jobs[i] = QueuedJob{fileId, vm.running[fileId].Priority, i}
+	}
+
+	return jobs
+}
+
+func (vm *verifierManager) emitPositions() {
+	vm.Lock()
+	queued := make([]*Verifier, len(vm.queue))
+	for i, fileId := range vm.queue {
+		queued[i] = vm.running[fileId]
+	}
+	vm.Unlock()
+
+	for i, v := range queued {
+		v.Emit("queue-position", fmt.Sprint(i))
+	}
+}
+
+// schedule starts queued jobs while there are free slots.
+func (vm *verifierManager) schedule() {
+	vm.Lock()
+
+	started := []*Verifier{}
+
+	for vm.active < vm.maxRunning && len(vm.queue) > 0 {
+		v := vm.running[vm.queue[0]]
+		vm.queue = vm.queue[1:]
+
+		// cancelled while queued
+		if v.GetState() != QUEUED {
+			continue
+		}
+
+		started = append(started, v)
+		vm.active++
+	}
+
+	vm.Unlock()
+
+	for _, v := range started {
+		go vm.run(v)
+	}
+
+	if len(started) > 0 {
+		vm.emitPositions()
+	}
+}
+
+func (vm *verifierManager) run(v *Verifier) {
+	if err := v.Run(); err != nil {
+		fmt.Println("run", v.File.Id, ":", err.Error())
+	}
+
+	vm.Lock()
+	vm.active--
+	vm.Unlock()
+
+	vm.schedule()
+}
+
+var VerifierManager verifierManager = verifierManager{
+	running: make(map[int64]*Verifier),
+	maxRunning: DEFAULT_MAX_RUNNING,
+}
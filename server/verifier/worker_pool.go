@@ -0,0 +1,80 @@
+package verifier
+
+import "sync"
+
+const DEFAULT_CONCURRENCY = 100
+
+// WorkerPool runs at most size functions at once. The size can be
+// changed while functions are running; the new limit applies to the
+// next function that is started.
+type WorkerPool struct {
+	size     int
+	inFlight int
+	cond     *sync.Cond
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+func NewWorkerPool(size int) *WorkerPool {
+	if size <= 0 {
+		size = DEFAULT_CONCURRENCY
+	}
+
+	p := &WorkerPool{size: size}
+	p.cond = sync.NewCond(&p.mu)
+
+	return p
+}
+
+// Go blocks until a worker is free and runs f on it.
+func (p *WorkerPool) Go(f func()) {
+	p.mu.Lock()
+	for p.inFlight >= p.size {
+		p.cond.Wait()
+	}
+	p.inFlight++
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+		defer p.release()
+		f()
+	}()
+}
+
+func (p *WorkerPool) release() {
+	p.mu.Lock()
+	p.inFlight--
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+// Wait blocks until every function started with Go has returned.
+func (p *WorkerPool) Wait() {
+	p.wg.Wait()
+}
+
+func (p *WorkerPool) SetSize(size int) {
+	if size <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	p.size = size
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+func (p *WorkerPool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+func (p *WorkerPool) InFlight() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.inFlight
+}
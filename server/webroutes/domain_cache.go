@@ -0,0 +1,28 @@
+package webroutes
+
+import (
+	"email_verify/respond"
+	"email_verify/verifier"
+	"encoding/json"
+	"net/http"
+)
+
+func (m *WebRoutesHandler) getDomainCache(w http.ResponseWriter, r *http.Request) {
+	res := struct {
+		respond.ResponseStruct
+		DomainCache []verifier.DomainInfo `json:"domainCache"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		DomainCache:    verifier.DomainCache.List(),
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
+
+// flushDomainCache flushes the domain given in the domain query value or
+// the whole cache when it isn't set.
+func (m *WebRoutesHandler) flushDomainCache(w http.ResponseWriter, r *http.Request) {
+	verifier.DomainCache.Flush(r.URL.Query().Get("domain"))
+
+	respond.RespondSuccess(w)
+}
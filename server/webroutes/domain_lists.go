@@ -0,0 +1,201 @@
+package webroutes
+
+import (
+	"bufio"
+	"email_verify/db"
+	"email_verify/respond"
+	"email_verify/schema"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func (m *WebRoutesHandler) getDomainLists(w http.ResponseWriter, r *http.Request) {
+	userId := r.URL.Query().Get("userId")
+	if userId == "" {
+		respond.RespondErrMsg(w, "userId invalid")
+		return
+	}
+
+	list := r.URL.Query().Get("list")
+	if list != "" && !schema.IsDomainList(list) {
+		respond.RespondErrMsg(w, "unknown list: " + list)
+		return
+	}
+
+	entries, err := db.GetDomainListEntries(m.db, userId, list)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		DomainList []schema.DomainListEntry `json:"domainList"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		DomainList:     entries,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
+
+func (m *WebRoutesHandler) insertDomainListEntry(w http.ResponseWriter, r *http.Request) {
+	var body schema.DomainListEntry
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if body.UserId == "" {
+		respond.RespondErrMsg(w, "Invalid userId")
+		return
+	}
+
+	if err := body.Validate(); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if err := db.SaveDomainListEntry(m.db, body); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	respond.RespondSuccess(w)
+}
+
+func (m *WebRoutesHandler) updateDomainListEntry(w http.ResponseWriter, r *http.Request) {
+	entryId, err := parseInt64PathValue("entryId", r)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	var body schema.DomainListEntry
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if body.UserId == "" {
+		respond.RespondErrMsg(w, "Invalid userId")
+		return
+	}
+
+	if err := body.Validate(); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	body.Id = entryId
+
+	if err := db.UpdateDomainListEntry(m.db, body); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	respond.RespondSuccess(w)
+}
+
+func (m *WebRoutesHandler) deleteDomainListEntry(w http.ResponseWriter, r *http.Request) {
+	userId := r.URL.Query().Get("userId")
+	if userId == "" {
+		respond.RespondErrMsg(w, "userId invalid")
+		return
+	}
+
+	entryId, err := parseInt64PathValue("entryId", r)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if err := db.DeleteDomainListEntry(m.db, userId, entryId); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	respond.RespondSuccess(w)
+}
+
+// importDomainList adds the domains of the uploaded text file to the list
+// given in the list query value. The file has one domain per line; blank
+// lines and lines starting with # are left out.
+func (m *WebRoutesHandler) importDomainList(w http.ResponseWriter, r *http.Request) {
+	userId := r.URL.Query().Get("userId")
+	if userId == "" {
+		respond.RespondErrMsg(w, "userId invalid")
+		return
+	}
+
+	list := r.URL.Query().Get("list")
+	if !schema.IsDomainList(list) {
+		respond.RespondErrMsg(w, "unknown list: " + list)
+		return
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+	defer file.Close()
+
+	domains := []string{}
+	invalid := []string{}
+	seen := map[string]struct{}{}
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		d, err := schema.NormalizeDomain(line)
+		if err != nil {
+			invalid = append(invalid, line)
+			continue
+		}
+
+		if _, ok := seen[d]; ok {
+			continue
+		}
+		seen[d] = struct{}{}
+
+		domains = append(domains, d)
+	}
+
+	if err := scanner.Err(); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	count, err := db.ImportDomainList(m.db, userId, list, domains)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		ImportedCount int64    `json:"importedCount"`
+		Invalid       []string `json:"invalid"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		ImportedCount:  count,
+		Invalid:        invalid,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
@@ -0,0 +1,105 @@
+package webroutes
+
+import (
+	"context"
+	"email_verify/respond"
+	"email_verify/schema"
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var exportHeader = []string{
+	"email", "reachable", "is_deliverable", "is_catch_all", "is_disposable",
+	"is_role_account", "is_free", "is_inbox_full", "error_code",
+	"score", "category", "matched_list", "original_email",
+}
+
+// exportEmails sends the verified emails of the file as a csv file.
+// category (comma separated) and minScore narrow down the emails sent.
+func (m *WebRoutesHandler) exportEmails(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	where := []string{"(file_id = ?)"}
+	args := []any{fileId}
+
+	if categories := r.URL.Query().Get("category"); categories != "" {
+		list := strings.Split(categories, ",")
+
+		for _, c := range list {
+			if !slices.Contains(schema.Categories, c) {
+				respond.RespondErrMsg(w, "unknown category: " + c)
+				return
+			}
+			args = append(args, c)
+		}
+
+		where = append(where, "(category in (?" + strings.Repeat(", ?", len(list)-1) + "))")
+	}
+
+	if r.URL.Query().Has("minScore") {
+		minScore, err := parseInt64QueryValue("minScore", r)
+		if err != nil {
+			respond.RespondErrMsg(w, err.Error())
+			return
+		}
+
+		where = append(where, "(score >= ?)")
+		args = append(args, minScore)
+	}
+
+	query := `select ` + emailDetailsColumns + ` from emails where ` + strings.Join(where, " and ")
+
+	// the whole file is read, which takes longer than the other queries.
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancelfunc()
+
+	rows, err := m.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"emails_" + strconv.FormatInt(fileId, 10) + ".csv\"")
+
+	cw := csv.NewWriter(w)
+	cw.Write(exportHeader)
+
+	for rows.Next() {
+		e, err := scanEmailDetails(rows)
+
+		if err != nil {
+			// the header is already sent so the error can only end the file.
+			break
+		}
+
+		cw.Write([]string{
+			e.EmailId,
+			e.Reachable,
+			strconv.FormatBool(e.IsDeliverable),
+			strconv.FormatBool(e.IsCatchAll),
+			strconv.FormatBool(e.IsDisposable),
+			strconv.FormatBool(e.IsRoleAccount),
+			strconv.FormatBool(e.IsFree),
+			strconv.FormatBool(e.IsInboxFull),
+			e.ErrorCode,
+			strconv.Itoa(e.Score),
+			e.Category,
+			e.MatchedList,
+			e.OriginalEmailId,
+		})
+	}
+
+	cw.Flush()
+}
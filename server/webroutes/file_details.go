@@ -7,6 +7,7 @@ import (
 	"email_verify/schema"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -23,7 +24,30 @@ func (m *WebRoutesHandler) getFileDetails(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	toVerifyCount, err := db.GetToVerifyCount(m.db, fileId)
+	// olderThanDays and outcomes (comma separated) count the emails a
+	// run with the same reverify options would check.
+	var reverify schema.ReverifyOptions
+
+	if days, err := parseInt64QueryValue("olderThanDays", r); err == nil {
+		reverify.OlderThanDays = int(days)
+	}
+
+	if outcomes := r.URL.Query().Get("outcomes"); outcomes != "" {
+		reverify.Outcomes = strings.Split(outcomes, ",")
+	}
+
+	if err := reverify.Validate(); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	toVerifyCount, err := db.GetToVerifyCount(m.db, fileId, reverify)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	pendingRecheckCount, err := db.GetPendingRecheckCount(m.db, fileId)
 	if err != nil {
 		respond.RespondErrMsg(w, err.Error())
 		return
@@ -31,12 +55,14 @@ func (m *WebRoutesHandler) getFileDetails(w http.ResponseWriter, r *http.Request
 
 	res := struct {
 		respond.ResponseStruct
-		EmailsCount   int64 `json:"emailsCount"`
-		ToVerifyCount int64 `json:"toVerifyCount"`
+		EmailsCount         int64 `json:"emailsCount"`
+		ToVerifyCount       int64 `json:"toVerifyCount"`
+		PendingRecheckCount int64 `json:"pendingRecheckCount"`
 	}{
-		ResponseStruct: respond.SUCCESS,
-		EmailsCount:    totalEmailCount,
-		ToVerifyCount:  toVerifyCount,
+		ResponseStruct:      respond.SUCCESS,
+		EmailsCount:         totalEmailCount,
+		ToVerifyCount:       toVerifyCount,
+		PendingRecheckCount: pendingRecheckCount,
 	}
 
 	json.NewEncoder(w).Encode(&res)
@@ -108,6 +134,21 @@ func (m *WebRoutesHandler) getFileListStatsLimit(w http.ResponseWriter, r *http.
 		list = append(list, d)
 	}
 
+	fileIds := make([]int64, len(list))
+	for i := range list {
+		fileIds[i] = list[i].FileId
+	}
+
+	categories, err := db.GetFilesCategoryStats(m.db, fileIds)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	for i := range list {
+		list[i].Categories = categories[list[i].FileId]
+	}
+
 	res := struct {
 		respond.ResponseStruct
 		StatsList []schema.FileStats `json:"statsList"`
@@ -173,6 +214,12 @@ func (m *WebRoutesHandler) getFileStats(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	d.Categories, err = db.GetCategoryStats(m.db, fileId)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
 	res := struct {
 		respond.ResponseStruct
 		FileStats schema.FileStats `json:"fileStats"`
@@ -183,3 +230,80 @@ func (m *WebRoutesHandler) getFileStats(w http.ResponseWriter, r *http.Request)
 
 	json.NewEncoder(w).Encode(&res)
 }
+
+func (m *WebRoutesHandler) getErrorCodeStats(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64QueryValue("fileId", r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	query := `
+	select error_code, count(*)
+	from emails
+	where file_id = ? and error_code != ''
+	group by error_code
+	order by count(*) desc`
+
+	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelfunc()
+
+	rows, err := m.db.QueryContext(ctx, query, fileId)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	defer rows.Close()
+
+	list := []schema.ErrorCodeCount{}
+
+	for rows.Next() {
+		var c schema.ErrorCodeCount
+
+		if err := rows.Scan(&c.ErrorCode, &c.Count); err != nil {
+			respond.RespondErrMsg(w, err.Error())
+			return
+		}
+
+		list = append(list, c)
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		ErrorCodeStats []schema.ErrorCodeCount `json:"errorCodeStats"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		ErrorCodeStats: list,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
+
+// getPendingRechecks lists the greylisted emails of the file that are
+// waiting to be checked again.
+func (m *WebRoutesHandler) getPendingRechecks(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	rechecks, err := db.GetRechecks(m.db, fileId)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		Rechecks []schema.EmailRecheck `json:"rechecks"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		Rechecks:       rechecks,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
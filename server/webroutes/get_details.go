@@ -2,6 +2,7 @@ package webroutes
 
 import (
 	"context"
+	"database/sql"
 	"email_verify/respond"
 	"email_verify/schema"
 	"encoding/json"
@@ -59,6 +60,45 @@ func (m *WebRoutesHandler) getAllFiles(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(&res)
 }
 
+// emailDetailsColumns are the emails columns in the order scanEmailDetails
+// reads them.
+const emailDetailsColumns = `file_id, email_id, is_valid_syntax, reachable,
+	is_deliverable, is_host_exists, has_mx_records, is_disposable,
+	is_catch_all, is_inbox_full, error_msg, error_code, is_role_account,
+	is_free, suggestion, has_gravatar, gravatar_url, verified_at, original_email_id,
+	score, category, matched_list`
+
+func scanEmailDetails(rows *sql.Rows) (schema.EmailDetails, error) {
+	var detail schema.EmailDetails
+
+	err := rows.Scan(
+		&detail.FileId,
+		&detail.EmailId,
+		&detail.IsValidSyntax,
+		&detail.Reachable,
+		&detail.IsDeliverable,
+		&detail.IsHostExists,
+		&detail.HasMxRecords,
+		&detail.IsDisposable,
+		&detail.IsCatchAll,
+		&detail.IsInboxFull,
+		&detail.ErrorMsg,
+		&detail.ErrorCode,
+		&detail.IsRoleAccount,
+		&detail.IsFree,
+		&detail.Suggestion,
+		&detail.HasGravatar,
+		&detail.GravatarUrl,
+		&detail.VerifiedAt,
+		&detail.OriginalEmailId,
+		&detail.Score,
+		&detail.Category,
+		&detail.MatchedList,
+	)
+
+	return detail, err
+}
+
 func (m *WebRoutesHandler) getEmailDetailsList(w http.ResponseWriter, r *http.Request) {
 	fileId, err := parseInt64PathValue("fileId", r)
 	if err != nil {
@@ -76,7 +116,7 @@ func (m *WebRoutesHandler) getEmailDetailsList(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	query := `call sp_get_email_details_from_limit(?, ?, ?)`
+	query := `select ` + emailDetailsColumns + ` from emails where file_id = ? limit ?, ?`
 
 	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancelfunc()
@@ -102,21 +142,9 @@ func (m *WebRoutesHandler) getEmailDetailsList(w http.ResponseWriter, r *http.Re
 	details := []schema.EmailDetails{}
 
 	for rows.Next() {
-		var detail schema.EmailDetails
-
-		if err := rows.Scan(
-			&detail.FileId,
-			&detail.EmailId,
-			&detail.IsValidSyntax,
-			&detail.Reachable,
-			&detail.IsDeliverable,
-			&detail.IsHostExists,
-			&detail.HasMxRecords,
-			&detail.IsDisposable,
-			&detail.IsCatchAll,
-			&detail.IsInboxFull,
-			&detail.ErrorMsg,
-		); err != nil {
+		detail, err := scanEmailDetails(rows)
+
+		if err != nil {
 			respond.RespondErrMsg(w, err.Error())
 			return
 		}
@@ -153,6 +181,20 @@ func translateDetailsFieldToDBField(field string) string {
 		return "is_catch_all"
 	case "isInboxFull":
 		return "is_inbox_full"
+	case "errorCode":
+		return "error_code"
+	case "isRoleAccount":
+		return "is_role_account"
+	case "isFree":
+		return "is_free"
+	case "suggestion":
+		return "suggestion"
+	case "hasGravatar":
+		return "has_gravatar"
+	case "category":
+		return "category"
+	case "matchedList":
+		return "matched_list"
 	}
 	return ""
 }
@@ -185,14 +227,21 @@ func (m *WebRoutesHandler) filterEmails(w http.ResponseWriter, r *http.Request)
 
 
 	for k, v := range body.FilterFields {
-		where = append(where, fmt.Sprintf("(%s = ?)", translateDetailsFieldToDBField(k)))
+		switch k {
+		case "minScore":
+			where = append(where, "(score >= ?)")
+		case "maxScore":
+			where = append(where, "(score <= ?)")
+		default:
+			where = append(where, fmt.Sprintf("(%s = ?)", translateDetailsFieldToDBField(k)))
+		}
 		args = append(args, v)
 	}
 
 	wh := strings.Join(where, "and")
 
-	query := fmt.Sprintf(`select * from emails where ((file_id = %d) and %s) limit %d, %d`,
-		body.FileId, wh, body.From, body.Limit,
+	query := fmt.Sprintf(`select %s from emails where ((file_id = %d) and %s) limit %d, %d`,
+		emailDetailsColumns, body.FileId, wh, body.From, body.Limit,
 	)
 
 	queryCount := fmt.Sprintf(`select count(*) from emails where ((file_id = %d) and %s)`,
@@ -214,21 +263,9 @@ func (m *WebRoutesHandler) filterEmails(w http.ResponseWriter, r *http.Request)
 	details := []schema.EmailDetails{}
 
 	for rows.Next() {
-		var detail schema.EmailDetails
-
-		if err := rows.Scan(
-			&detail.FileId,
-			&detail.EmailId,
-			&detail.IsValidSyntax,
-			&detail.Reachable,
-			&detail.IsDeliverable,
-			&detail.IsHostExists,
-			&detail.HasMxRecords,
-			&detail.IsDisposable,
-			&detail.IsCatchAll,
-			&detail.IsInboxFull,
-			&detail.ErrorMsg,
-		); err != nil {
+		detail, err := scanEmailDetails(rows)
+
+		if err != nil {
 			respond.RespondErrMsg(w, err.Error())
 			return
 		}
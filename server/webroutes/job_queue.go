@@ -0,0 +1,160 @@
+package webroutes
+
+import (
+	"email_verify/respond"
+	"email_verify/verifier"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+func (m *WebRoutesHandler) getJobQueue(w http.ResponseWriter, r *http.Request) {
+	res := struct {
+		respond.ResponseStruct
+		Queue []verifier.QueuedJob `json:"queue"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		Queue:          verifier.VerifierManager.Queue(),
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
+
+// enqueueVerification creates a verifier for the file from the body and
+// queues it, for clients that don't hold a verification socket.
+func (m *WebRoutesHandler) enqueueVerification(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	var body struct {
+		verifier.VerifierOptions
+		Priority int `json:"priority"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if v := verifier.VerifierManager.Get(fileId); v != nil {
+		switch v.GetState() {
+		case verifier.RUNNING, verifier.PAUSED, verifier.QUEUED:
+			respond.RespondErrMsg(w, "verifier is already running or queued.")
+			return
+		}
+	}
+
+	v, err := verifier.NewVerifier(fileId, body.VerifierOptions, m.db)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	verifier.VerifierManager.Add(fileId, v)
+
+	if err := verifier.VerifierManager.Enqueue(fileId, body.Priority); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		Position int `json:"position"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		Position:       verifier.VerifierManager.Position(fileId),
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
+
+func (m *WebRoutesHandler) moveJob(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	position, err := parseInt64QueryValue("position", r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if err := verifier.VerifierManager.Move(fileId, int(position)); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	respond.RespondSuccess(w)
+}
+
+func (m *WebRoutesHandler) setJobPriority(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	priority, err := parseInt64QueryValue("priority", r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if err := verifier.VerifierManager.SetPriority(fileId, int(priority)); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	respond.RespondSuccess(w)
+}
+
+func (m *WebRoutesHandler) setMaxRunningJobs(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.URL.Query().Get("max"))
+
+	if err != nil || n <= 0 {
+		respond.RespondErrMsg(w, "max must be a number greater than 0.")
+		return
+	}
+
+	verifier.VerifierManager.SetMaxRunning(n)
+
+	respond.RespondSuccess(w)
+}
+
+// getVerifierDetails returns a snapshot of the verifier of the file, the
+// same data the get-verifier-details socket event sends.
+func (m *WebRoutesHandler) getVerifierDetails(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	v := verifier.VerifierManager.Get(fileId)
+	if v == nil {
+		respond.RespondErrMsg(w, "verifier not found.")
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		Verifier verifier.VerifierData `json:"verifier"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		Verifier:       v.Snapshot(),
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
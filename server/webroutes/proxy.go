@@ -2,6 +2,7 @@ package webroutes
 
 import (
 	"context"
+	"email_verify/db"
 	"email_verify/respond"
 	"email_verify/schema"
 	"encoding/json"
@@ -17,39 +18,16 @@ func (m *WebRoutesHandler) getProxyList(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	q := `call sp_get_proxy_list(?)`
-
-	ctx, cancelfunc := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancelfunc()
-
-	rows, err := m.db.QueryContext(ctx, q, userId)
+	list, err := db.GetProxyList(m.db, userId)
 
 	if err != nil {
 		respond.RespondErrMsg(w, err.Error())
 		return
 	}
-	defer rows.Close()
-
-	list := []schema.ProxyDetails{}
 
-	for rows.Next() {
-		var p schema.ProxyDetails
-
-		if err := rows.Scan(
-			&p.Id,
-			&p.Proto,
-			&p.Host,
-			&p.Port,
-			&p.Name,
-			&p.Password,
-			&p.IsInUse,
-			&p.IsEnabled,
-		); err != nil {
-			respond.RespondErrMsg(w, err.Error())
-			return
-		}
-
-		list = append(list, p)
+	if err := db.MergeProxyHealth(m.db, list); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
 	}
 
 	res := struct {
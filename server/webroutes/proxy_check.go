@@ -0,0 +1,133 @@
+package webroutes
+
+import (
+	"email_verify/db"
+	"email_verify/proxycheck"
+	"email_verify/respond"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type proxyCheckResult struct {
+	ProxyId int64 `json:"proxyId"`
+	proxycheck.Result
+}
+
+// parseProbeTarget reads the optional probe target from the body. An
+// empty body tests against proxycheck.DefaultTarget.
+func parseProbeTarget(r *http.Request) (proxycheck.Target, error) {
+	var t proxycheck.Target
+
+	err := json.NewDecoder(r.Body).Decode(&t)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return t, err
+	}
+
+	return t.WithDefaults(), nil
+}
+
+func (m *WebRoutesHandler) testProxy(w http.ResponseWriter, r *http.Request) {
+	userId := r.URL.Query().Get("userId")
+
+	if userId == "" {
+		respond.RespondErrMsg(w, "userId invalid")
+		return
+	}
+
+	proxyId, err := parseInt64PathValue("proxyId", r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	target, err := parseProbeTarget(r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	list, err := db.GetProxyList(m.db, userId)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	for _, p := range list {
+		if p.Id != proxyId {
+			continue
+		}
+
+		res := struct {
+			respond.ResponseStruct
+			Result proxyCheckResult `json:"result"`
+		}{
+			respond.SUCCESS,
+			proxyCheckResult{p.Id, proxycheck.Check(p.URI(), target)},
+		}
+
+		json.NewEncoder(w).Encode(&res)
+		return
+	}
+
+	respond.RespondErrMsg(w, "proxy not found.")
+}
+
+func (m *WebRoutesHandler) testAllProxies(w http.ResponseWriter, r *http.Request) {
+	userId := r.URL.Query().Get("userId")
+
+	if userId == "" {
+		respond.RespondErrMsg(w, "userId invalid")
+		return
+	}
+
+	target, err := parseProbeTarget(r)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	list, err := db.GetProxyList(m.db, userId)
+
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	results := []proxyCheckResult{}
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, p := range list {
+		if !p.IsEnabled {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := proxyCheckResult{p.Id, proxycheck.Check(p.URI(), target)}
+			mutex.Lock()
+			results = append(results, res)
+			mutex.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	res := struct {
+		respond.ResponseStruct
+		Results []proxyCheckResult `json:"results"`
+	}{
+		respond.SUCCESS,
+		results,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
@@ -0,0 +1,63 @@
+package webroutes
+
+import (
+	"email_verify/db"
+	"email_verify/respond"
+	"email_verify/schema"
+	"encoding/json"
+	"net/http"
+)
+
+func (m *WebRoutesHandler) getScoreWeights(w http.ResponseWriter, r *http.Request) {
+	userId := r.URL.Query().Get("userId")
+	if userId == "" {
+		respond.RespondErrMsg(w, "invalid userId")
+		return
+	}
+
+	weights, err := db.GetScoreWeights(m.db, userId)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		ScoreWeights schema.ScoreWeights `json:"scoreWeights"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		ScoreWeights:   weights,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
+
+// updateScoreWeights saves the weights the user's next runs are scored
+// with. Emails that were already scored keep their score until they are
+// verified again.
+func (m *WebRoutesHandler) updateScoreWeights(w http.ResponseWriter, r *http.Request) {
+	userId := r.URL.Query().Get("userId")
+	if userId == "" {
+		respond.RespondErrMsg(w, "invalid userId")
+		return
+	}
+
+	weights := schema.DefaultScoreWeights()
+
+	if err := json.NewDecoder(r.Body).Decode(&weights); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if err := weights.Validate(); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if err := db.SaveScoreWeights(m.db, userId, weights); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	respond.RespondSuccess(w)
+}
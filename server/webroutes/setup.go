@@ -45,6 +45,8 @@ func (m *WebRoutesHandler) deleteFileRoute(w http.ResponseWriter, r *http.Reques
 func (m *WebRoutesHandler) setupProxyRoutes() {
 	m.mux.HandleFunc("GET /get-proxy-list", m.getProxyList)
 	m.mux.HandleFunc("POST /insert-proxy", m.insertProxy)
+	m.mux.HandleFunc("POST /{proxyId}/test-proxy", m.testProxy)
+	m.mux.HandleFunc("POST /test-all-proxies", m.testAllProxies)
 	m.mux.HandleFunc("PUT /{proxyId}/update-proxy", m.updateProxy)
 	m.mux.HandleFunc("PUT /{proxyId}/update-proxy-is-enabled", m.updateProxyIsEnabled)
 	m.mux.HandleFunc("DELETE /{proxyId}/delete-proxy", m.deleteProxy)
@@ -53,8 +55,10 @@ func (m *WebRoutesHandler) setupProxyRoutes() {
 func (m *WebRoutesHandler) setupFileRoutes() {
 	m.mux.HandleFunc("GET /get-all-files", m.getAllFiles)
 	m.mux.HandleFunc("GET /{fileId}/get-file-details", m.getFileDetails)
+	m.mux.HandleFunc("GET /{fileId}/get-pending-rechecks", m.getPendingRechecks)
 	m.mux.HandleFunc("GET /get-file-list-stats", m.getFileListStatsLimit)
 	m.mux.HandleFunc("GET /get-file-stats", m.getFileStats)
+	m.mux.HandleFunc("GET /get-error-code-stats", m.getErrorCodeStats)
 
 	m.mux.HandleFunc("POST /upload-file", m.uploadFile)
 
@@ -64,14 +68,56 @@ func (m *WebRoutesHandler) setupFileRoutes() {
 func (m *WebRoutesHandler) setupEmailRoutes() {
 	m.mux.HandleFunc("GET /{fileId}/get-email-details-list", m.getEmailDetailsList)
 
+	m.mux.HandleFunc("GET /get-checker-list", m.getCheckerList)
+
 	m.mux.HandleFunc("POST /verify-emails", m.verifyEmails)
 	m.mux.HandleFunc("POST /filter-emails", m.filterEmails)
+
+	m.mux.HandleFunc("GET /{fileId}/get-suggestions", m.getSuggestions)
+	m.mux.HandleFunc("POST /{fileId}/accept-suggestions", m.acceptSuggestions)
+
+	m.mux.HandleFunc("GET /{fileId}/export-emails", m.exportEmails)
+
+	m.mux.HandleFunc("GET /get-score-weights", m.getScoreWeights)
+	m.mux.HandleFunc("PUT /update-score-weights", m.updateScoreWeights)
+}
+
+func (m *WebRoutesHandler) setupDomainCacheRoutes() {
+	m.mux.HandleFunc("GET /get-domain-cache", m.getDomainCache)
+	m.mux.HandleFunc("DELETE /flush-domain-cache", m.flushDomainCache)
+}
+
+func (m *WebRoutesHandler) setupJobQueueRoutes() {
+	m.mux.HandleFunc("GET /get-job-queue", m.getJobQueue)
+	m.mux.HandleFunc("GET /{fileId}/get-verifier-details", m.getVerifierDetails)
+	m.mux.HandleFunc("POST /{fileId}/enqueue-verification", m.enqueueVerification)
+	m.mux.HandleFunc("PUT /{fileId}/move-job", m.moveJob)
+	m.mux.HandleFunc("PUT /{fileId}/set-job-priority", m.setJobPriority)
+	m.mux.HandleFunc("PUT /set-max-running-jobs", m.setMaxRunningJobs)
+}
+
+func (m *WebRoutesHandler) setupDomainListRoutes() {
+	m.mux.HandleFunc("GET /get-domain-lists", m.getDomainLists)
+	m.mux.HandleFunc("POST /insert-domain-list-entry", m.insertDomainListEntry)
+	m.mux.HandleFunc("POST /import-domain-list", m.importDomainList)
+	m.mux.HandleFunc("PUT /{entryId}/update-domain-list-entry", m.updateDomainListEntry)
+	m.mux.HandleFunc("DELETE /{entryId}/delete-domain-list-entry", m.deleteDomainListEntry)
+}
+
+func (m *WebRoutesHandler) setupUserRoutes() {
+	m.mux.HandleFunc("GET /get-user-profile", m.getUserProfile)
+	m.mux.HandleFunc("PUT /update-user-profile", m.updateUserProfile)
 }
 
 func (m *WebRoutesHandler) setupRoutes() {
 	m.setupFileRoutes()
 	m.setupEmailRoutes()
 	m.setupProxyRoutes()
+	m.setupDomainCacheRoutes()
+	m.setupJobQueueRoutes()
+	m.setupDomainListRoutes()
+	m.setupUserRoutes()
 
 	m.mux.HandleFunc("/{fileId}/verification-ws", m.verificationWsConn)
+	m.mux.HandleFunc("GET /{fileId}/verification-events", m.verificationEvents)
 }
@@ -0,0 +1,42 @@
+package webroutes
+
+import (
+	"email_verify/respond"
+	"email_verify/socket"
+	"net/http"
+	"strconv"
+)
+
+// verificationEvents streams the events of the file's verifier as
+// Server-Sent Events, for clients that only watch a run. It sends the
+// same events as verificationWsConn. The number of the last event a
+// client got is taken from the Last-Event-ID header browsers send when
+// they reconnect, or from lastSeq.
+func (m *WebRoutesHandler) verificationEvents(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	lastSeq, err := parseInt64QueryValue("lastSeq", r)
+	replay := err == nil
+
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		if seq, err := strconv.ParseInt(id, 10, 64); err == nil {
+			lastSeq, replay = seq, true
+		}
+	}
+
+	s, err := socket.NewSSESocket(w, r)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	unsubscribe := watchVerifier(s, fileId, lastSeq, replay)
+
+	s.Listen()
+	unsubscribe()
+	s.Close()
+}
@@ -0,0 +1,110 @@
+package webroutes
+
+import (
+	"email_verify/db"
+	"email_verify/respond"
+	"email_verify/schema"
+	"email_verify/verifier"
+	"encoding/json"
+	"net/http"
+)
+
+// getSuggestions lists the emails of the file with a domain typo
+// suggestion waiting for review.
+func (m *WebRoutesHandler) getSuggestions(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+	from, err := parseInt64QueryValue("from", r)
+	if err != nil {
+		from = 0
+	}
+	limit, err := parseInt64QueryValue("limit", r)
+	if err != nil {
+		limit = 500
+	}
+
+	suggestions, err := db.GetSuggestions(m.db, fileId, from, limit)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	count, err := db.GetSuggestionCount(m.db, fileId)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		Suggestions     []schema.EmailSuggestion `json:"suggestions"`
+		SuggestionCount int64                    `json:"suggestionCount"`
+	}{
+		ResponseStruct:  respond.SUCCESS,
+		Suggestions:     suggestions,
+		SuggestionCount: count,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
+
+// acceptSuggestions rewrites the picked emails, or all of them, to their
+// suggested address and queues the corrected ones for verification.
+func (m *WebRoutesHandler) acceptSuggestions(w http.ResponseWriter, r *http.Request) {
+	fileId, err := parseInt64PathValue("fileId", r)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	var body struct {
+		EmailIds []string `json:"emailIds"`
+		All      bool     `json:"all"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if !body.All && len(body.EmailIds) == 0 {
+		respond.RespondErrMsg(w, "no emails to accept")
+		return
+	}
+
+	accepted, skipped, err := db.AcceptSuggestions(m.db, fileId, body.EmailIds, body.All)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	emails := make([]string, len(accepted))
+	for i, a := range accepted {
+		emails[i] = a.EmailId
+	}
+
+	// userId is optional, the corrected emails are scored with the
+	// default weights without it. Emails that fail to be queued have no
+	// results, so the next run of the file verifies them.
+	userId := r.URL.Query().Get("userId")
+
+	if err := verifier.RecheckQueue.AddEmails(fileId, userId, emails); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		Accepted []schema.AcceptedSuggestion `json:"accepted"`
+		Skipped  []schema.SkippedSuggestion  `json:"skipped"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		Accepted:       accepted,
+		Skipped:        skipped,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
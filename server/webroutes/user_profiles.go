@@ -0,0 +1,61 @@
+package webroutes
+
+import (
+	"email_verify/db"
+	"email_verify/respond"
+	"email_verify/schema"
+	"encoding/json"
+	"net/http"
+)
+
+func (m *WebRoutesHandler) getUserProfile(w http.ResponseWriter, r *http.Request) {
+	userId := r.URL.Query().Get("userId")
+	if userId == "" {
+		respond.RespondErrMsg(w, "userId invalid")
+		return
+	}
+
+	p, err := db.GetUserProfile(m.db, userId)
+	if err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	res := struct {
+		respond.ResponseStruct
+		UserProfile schema.UserProfile `json:"userProfile"`
+	}{
+		ResponseStruct: respond.SUCCESS,
+		UserProfile:    p,
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
+
+// updateUserProfile saves the profile the user's next runs start from.
+// Runs that already started keep the values they recorded.
+func (m *WebRoutesHandler) updateUserProfile(w http.ResponseWriter, r *http.Request) {
+	var body schema.UserProfile
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if body.UserId == "" {
+		respond.RespondErrMsg(w, "Invalid userId")
+		return
+	}
+
+	if err := body.SMTPIdentity.Validate(); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	if err := db.SaveUserProfile(m.db, body); err != nil {
+		respond.RespondErrMsg(w, err.Error())
+		return
+	}
+
+	respond.RespondSuccess(w)
+}
@@ -46,3 +46,17 @@ func (m *WebRoutesHandler) verifyEmails(w http.ResponseWriter, r *http.Request)
 
 	json.NewEncoder(w).Encode(res)
 }
+
+func (m *WebRoutesHandler) getCheckerList(w http.ResponseWriter, r *http.Request) {
+	res := struct {
+		respond.ResponseStruct
+		Checkers []string `json:"checkers"`
+		DefaultChecker string `json:"defaultChecker"`
+	} {
+		respond.SUCCESS,
+		verifier.CheckerNames(),
+		verifier.DefaultChecker(),
+	}
+
+	json.NewEncoder(w).Encode(&res)
+}
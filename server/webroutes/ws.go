@@ -2,19 +2,21 @@ package webroutes
 
 import (
 	"database/sql"
+	"email_verify/db"
 	"email_verify/respond"
 	"email_verify/socket"
 	"email_verify/verifier"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/websocket"
 )
 
 var upgrader = websocket.Upgrader{}
 
-func listenEvents(ws socket.Socket, fileId int64, db *sql.DB) {
+func listenEvents(ws socket.Socket, fileId int64, dbConn *sql.DB) {
 	ws.On("get-verifier-details", func(_ []byte) {
 		v := verifier.VerifierManager.Get(fileId)
 		if v == nil {
@@ -22,54 +24,140 @@ func listenEvents(ws socket.Socket, fileId int64, db *sql.DB) {
 			return
 		}
 
-		socket.EmitWs(ws, "get-verifier-details-res", v.VerifierData)
+		socket.EmitWs(ws, "get-verifier-details-res", v.Snapshot())
 	})
 
 	ws.On("create-verifier", func(b []byte) {
-		var data struct {
-			EmailCount int `json:"emailCount"`
-			BatchSize int `json:"batchSize"`
-			RetryCount int `json:"retryCount"`
-			DelayMs int `json:"delayMs"`
-			Proxies []string `json:"proxies"`
-		}
+		var data verifier.VerifierOptions
 
 		if err := json.Unmarshal(b, &data); err != nil {
 			ws.EmitErr("create-verifier-res", err.Error()).Close()
 			return
 		}
 
-		v := verifier.NewVerifier(
-			fileId,
-			data.EmailCount,
-			data.BatchSize,
-			data.RetryCount,
-			data.DelayMs,
-			data.Proxies,
-			db,
-			ws,
-		)
+		if v := verifier.VerifierManager.Get(fileId); v != nil {
+			switch v.GetState() {
+			case verifier.RUNNING, verifier.PAUSED, verifier.QUEUED:
+				ws.EmitErr("create-verifier-res", "verifier is already running or queued.")
+				return
+			}
+		}
+
+		v, err := verifier.NewVerifier(fileId, data, dbConn)
+
+		if err != nil {
+			ws.EmitErr("create-verifier-res", err.Error()).Close()
+			return
+		}
 
 		verifier.VerifierManager.Add(fileId, v)
 
 		socket.EmitWs(ws, "create-verifier-res", respond.SUCCESS)
+		v.Emit("status", v.GetState())
 	})
 
 	ws.On("remove-verifier", func(b []byte) {
-		verifier.VerifierManager.Remove(fileId)
+		// a running verifier takes a while to stop, which the socket
+		// doesn't wait for. It stays in the manager until then, so no
+		// other run starts on the file.
+		go func() {
+			if !verifier.VerifierManager.Remove(fileId) {
+				return
+			}
+			if err := db.DeleteRun(dbConn, fileId); err != nil {
+				fmt.Println(err.Error())
+			}
+			verifier.EventHub.Emit(fileId, "status", verifier.NOT_CREATED)
+		}()
 	})
 
-	ws.On("run-verifier", func(_ []byte) {
+	ws.On("run-verifier", func(b []byte) {
+		var data struct {
+			Priority int `json:"priority"`
+		}
+
+		// the payload is optional
+		json.Unmarshal(b, &data)
+
+		if err := verifier.VerifierManager.Enqueue(fileId, data.Priority); err != nil {
+			ws.EmitErr("run-verifier-err", err.Error())
+			return
+		}
+
 		if v := verifier.VerifierManager.Get(fileId); v != nil {
-			err := v.Run()
+			v.Emit("status", v.GetState())
+		}
+	})
 
-			if err != nil {
-				ws.EmitErr("run-verifier-err", err.Error()).Close()
-			}
-		} else {
-			ws.EmitErr("run-verifier-err", "verifier not found.").Close()
+	ws.On("set-concurrency", func(b []byte) {
+		var data struct {
+			Concurrency int `json:"concurrency"`
+		}
+
+		if err := json.Unmarshal(b, &data); err != nil {
+			ws.EmitErr("set-concurrency-res", err.Error())
+			return
 		}
+
+		v := verifier.VerifierManager.Get(fileId)
+		if v == nil {
+			ws.EmitErr("set-concurrency-res", "verifier not found.")
+			return
+		}
+
+		if err := v.SetConcurrency(data.Concurrency); err != nil {
+			ws.EmitErr("set-concurrency-res", err.Error())
+			return
+		}
+
+		socket.EmitWs(ws, "set-concurrency-res", respond.SUCCESS)
 	})
+
+	controlEvent := func(ev string, f func(*verifier.Verifier) error) {
+		ws.On(ev, func(_ []byte) {
+			v := verifier.VerifierManager.Get(fileId)
+			if v == nil {
+				ws.EmitErr(ev+"-res", "verifier not found.")
+				return
+			}
+
+			if err := f(v); err != nil {
+				ws.EmitErr(ev+"-res", err.Error())
+				return
+			}
+
+			socket.EmitWs(ws, ev+"-res", respond.SUCCESS)
+			v.Emit("status", v.GetState())
+		})
+	}
+
+	controlEvent("pause-verifier", (*verifier.Verifier).Pause)
+	controlEvent("resume-verifier", (*verifier.Verifier).Resume)
+	controlEvent("cancel-verifier", verifier.VerifierManager.Cancel)
+}
+
+// watchVerifier sends s the status of the file's verifier and subscribes
+// it to the verifier's events, including the ones of a verifier created
+// later, until the returned func is called. With replay set the events
+// after lastSeq are sent first.
+func watchVerifier(s socket.Socket, fileId int64, lastSeq int64, replay bool) func() {
+	v := verifier.VerifierManager.Get(fileId)
+
+	if v == nil {
+		s.Emit("status", verifier.NOT_CREATED)
+		return verifier.EventHub.Subscribe(fileId, s)
+	}
+
+	s.Emit("status", v.GetState())
+	if pos := verifier.VerifierManager.Position(fileId); pos != -1 {
+		s.Emit("queue-position", strconv.Itoa(pos))
+	}
+
+	if replay {
+		return v.SubscribeFrom(s, lastSeq)
+	}
+
+	return verifier.EventHub.Subscribe(fileId, s)
 }
 
 func (m *WebRoutesHandler) verificationWsConn(w http.ResponseWriter, r *http.Request) {
@@ -88,15 +176,16 @@ func (m *WebRoutesHandler) verificationWsConn(w http.ResponseWriter, r *http.Req
 
 	fmt.Println("socket connected", fileId)
 
-	if v := verifier.VerifierManager.Get(fileId); v != nil {
-		ws.Emit("status", v.State)
-		v.SetWs(ws)
-	} else {
-		ws.Emit("status", verifier.NOT_CREATED)
-	}
+	// a client that reconnects sends the number of the last event it got
+	// as lastSeq to be sent the ones it missed first.
+	lastSeq, err := parseInt64QueryValue("lastSeq", r)
+	unsubscribe := watchVerifier(ws, fileId, lastSeq, err == nil)
 
 	listenEvents(ws, fileId, m.db)
 
 	ws.Listen()
+	unsubscribe()
 	ws.Close()
+
+	fmt.Println("socket disconnected", fileId)
 }